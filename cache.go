@@ -0,0 +1,112 @@
+package yandex
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache хранилище ответов API. Ключ — URL запроса без API ключа, значение — тело ответа.
+// Реализации должны быть безопасны для конкурентного использования.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// DefaultCacheTTL время жизни ответов в кеше по методам API.
+// Ответы методов, которых нет в списке, не кешируются.
+var DefaultCacheTTL = map[string]time.Duration{
+	"stations_list":      24 * time.Hour,
+	"schedule":           6 * time.Hour,
+	"search":             time.Hour,
+	"thread":             6 * time.Hour,
+	"nearest_stations":   24 * time.Hour,
+	"nearest_settlement": 24 * time.Hour,
+	"carrier":            24 * time.Hour,
+	"copyright":          24 * time.Hour,
+}
+
+// cacheTTL возвращает время жизни ответа метода в кеше, 0 — не кешировать
+func (c *config) cacheTTL(endpoint string) time.Duration {
+	if ttl, ok := c.CacheTTL[endpoint]; ok {
+		return ttl
+	}
+	return DefaultCacheTTL[endpoint]
+}
+
+type cacheBypassKey struct{}
+
+// WithoutCache возвращает контекст, запросы с которым не читают ответ из кеша и не сохраняют его
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// LRUCache кеш в памяти, вытесняющий давно не использованные ответы
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+	now   func() time.Time
+}
+
+type lruItem struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache возвращает кеш в памяти не более чем на size ответов
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:  size,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		now:   time.Now,
+	}
+}
+
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := e.Value.(*lruItem)
+	if !c.now().Before(item.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return item.value, true
+}
+
+func (c *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	c.items[key] = c.order.PushFront(&lruItem{
+		key:     key,
+		value:   value,
+		expires: c.now().Add(ttl),
+	})
+	for c.size > 0 && c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRUCache) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.items, e.Value.(*lruItem).key)
+}
@@ -0,0 +1,140 @@
+package yandex
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingCache запоминает ключи, с которыми к нему обращались
+type recordingCache struct {
+	*LRUCache
+	mu   sync.Mutex
+	keys []string
+}
+
+func (c *recordingCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	c.keys = append(c.keys, key)
+	c.mu.Unlock()
+	c.LRUCache.Set(key, value, ttl)
+}
+
+func TestClient_Cache(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return okResponse(`{"copyright": {"text": "ok"}}`), nil
+	})
+	cache := &recordingCache{LRUCache: NewLRUCache(10)}
+	c.cfg.Cache = cache
+	c.pool = NewKeyPool([]string{secretKey}, KeyPoolConfig{})
+
+	for i := 0; i < 3; i++ {
+		resp, err := c.Copyright(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp.Copyright.Text)
+	}
+	assert.Equal(t, 1, calls)
+
+	_, err := c.Copyright(WithoutCache(context.TODO()))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	require.Len(t, cache.keys, 1)
+	assert.NotContains(t, cache.keys[0], secretKey)
+	assert.Contains(t, cache.keys[0], "/copyright/")
+}
+
+func TestClient_CacheTTL(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return okResponse(`{}`), nil
+	})
+	c.cfg.Cache = NewLRUCache(10)
+	c.cfg.CacheTTL = map[string]time.Duration{"copyright": 0}
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Copyright(context.TODO())
+		require.NoError(t, err)
+		_, err = c.StationsList(context.TODO())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func TestClient_CacheSkipsErrors(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusNotFound), nil
+	})
+	c.cfg.Cache = NewLRUCache(10)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Copyright(context.TODO())
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestLRUCache(t *testing.T) {
+	now := time.Now()
+	c := NewLRUCache(2)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("b", []byte("2"), time.Minute)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+	c.Set("c", []byte("3"), time.Minute)
+
+	_, ok = c.Get("b")
+	assert.False(t, ok, "least recently used entry must be evicted")
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(v))
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("c")
+	assert.False(t, ok, "expired entry must not be returned")
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ya-rasp-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewDiskCache(dir)
+	require.NoError(t, err)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	key := "https://api.rasp.yandex.net/v3.0/stations_list/?format=json"
+	c.Set(key, []byte(`{"countries": []}`), time.Hour)
+
+	v, ok := c.Get(key)
+	require.True(t, ok)
+	assert.Equal(t, `{"countries": []}`, string(v))
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.False(t, strings.Contains(files[0].Name(), "stations_list"))
+
+	now = now.Add(time.Hour)
+	_, ok = c.Get(key)
+	assert.False(t, ok)
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 0)
+}
@@ -1,6 +1,21 @@
 package yandex
 
+// Перевозчик
 type Carrier struct {
-  Code int `json:"code"`
-  Title string `json:"title"`
+	Code     int          `json:"code" xml:"code"`         // Код перевозчика в Яндекс Расписаниях
+	Title    string       `json:"title" xml:"title"`       // Название перевозчика
+	Codes    CarrierCodes `json:"codes" xml:"codes"`       // Коды перевозчика в других системах кодирования
+	Address  string       `json:"address" xml:"address"`   // Юридический адрес
+	URL      string       `json:"url" xml:"url"`           // Сайт перевозчика
+	Logo     string       `json:"logo" xml:"logo"`         // Ссылка на логотип перевозчика
+	Email    string       `json:"email" xml:"email"`       // Электронная почта
+	Phone    string       `json:"phone" xml:"phone"`       // Контактный телефон
+	Contacts string       `json:"contacts" xml:"contacts"` // Контактная информация в свободной форме
+}
+
+// Коды перевозчика в системах кодирования
+type CarrierCodes struct {
+	Sirena string `json:"sirena" xml:"sirena"`
+	Iata   string `json:"iata" xml:"iata"`
+	Icao   string `json:"icao" xml:"icao"`
 }
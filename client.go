@@ -1,24 +1,25 @@
 package yandex
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
-	"fmt"
+	"io"
 	"io/ioutil"
-	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"time"
 )
 
 const (
-	dateFormat  = "2006-01-02"
-	scheme      = "https"
-	defaultHost = "api.rasp.yandex.net"
-	apiVersion  = "v3.0"
+	dateFormat    = "2006-01-02"
+	defaultScheme = "https"
+	defaultHost   = "api.rasp.yandex.net"
+	apiVersion    = "v3.0"
 )
 
 type Client interface {
@@ -26,6 +27,8 @@ type Client interface {
 	Schedules(ctx context.Context, req SchedulesRequest) (*SchedulesResponse, error)
 	// Список всех доступных станций
 	StationsList(ctx context.Context) (*StationsListResponse, error)
+	// Обход списка станций без загрузки всего списка в память
+	StationsListStream(ctx context.Context, filter StationsFilter, fn StationsFunc) error
 	// Расписание рейсов между станциями
 	Search(ctx context.Context, req SearchRequest) (*SearchResponse, error)
 	// Список станций следования
@@ -34,103 +37,94 @@ type Client interface {
 	NearestStations(ctx context.Context, req NearestStationsRequest) (*NearestStationsResponse, error)
 	//
 	NearestCity(ctx context.Context, req NearestCityRequest) (*NearestCityResponse, error)
+	// Информация о перевозчике
+	Carrier(ctx context.Context, req CarrierRequest) (*CarrierResponse, error)
+	// Копирайт Яндекс Расписаний
+	Copyright(ctx context.Context) (*CopyrightResponse, error)
 }
 
 type client struct {
 	client *http.Client
-	cfg    *Config
-	keys   []string
-	idxKey int
+	cfg    *config
+	pool   *KeyPool
+	doer   Doer    // Цепочка middleware, nil — без middleware
+	log    *logger // Журнал событий, nil — без журнала
 }
 
-// New return client
-func New(cfg *Config) Client {
-	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		MaxIdleConns:          100,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	}
-	httpClient := &http.Client{
-		Timeout:   cfg.Timeout,
-		Transport: transport,
-	}
-	return &client{
-		client: httpClient,
-		cfg:    cfg,
-	}
+type SchedulesRequest struct {
+	Station        string         //
+	Time           time.Time      //
+	TransportType  TransportType  //
+	Event          event          // Событие: отправление или прибытие, по умолчанию отправление
+	Direction      string         // Код направления электричек, значение «all» — все направления
+	System         system         // Система кодирования для Station, по умолчанию yandex
+	ShowSystems    system         // Система кодирования для кодов станций в ответе
+	ResultTimezone *time.Location // Часовой пояс для дат и времени в ответе, по умолчанию часовой пояс станции
+	Offset         int
+	Limit          int
 }
 
-// NewWithDefaultConfig return client with default cfg
-func NewWithDefaultConfig(apiKey string) Client {
-	return NewWithPoolKey(apiKey)
+type SchedulesResponse struct {
+	Pagination        Pagination  `json:"pagination" xml:"pagination"`                 // Информация о постраничном выводе найденных рейсов.
+	Date              string      `json:"date" xml:"date"`                             // Дата, на которую получен список рейсов.
+	Station           Station     `json:"station" xml:"station"`                       // Информация об указанной в запросе станции.
+	Schedule          []Schedule  `json:"schedule" xml:"schedule>item"`                // Список рейсов.
+	ScheduleDirection Direction   `json:"schedule_direction" xml:"schedule_direction"` // Код и название запрошенного направления рейсов.
+	Directions        []Direction `json:"directions" xml:"directions>direction"`       // Коды и названия возможных направлений движения электричек по станции.
 }
 
-func NewWithPoolKey(keys ...string) Client {
-	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		MaxIdleConns:          100,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
+func (c *client) Schedules(ctx context.Context, req SchedulesRequest) (*SchedulesResponse, error) {
+	if req.Station == "" {
+		return nil, validationError("station are missing")
 	}
-	httpClient := &http.Client{
-		Timeout:   15 * time.Second,
-		Transport: transport,
+	if req.TransportType != "" && !req.TransportType.valid() {
+		return nil, validationError("unknown transport type %q", req.TransportType)
 	}
-
-	return &client{
-		client: httpClient,
-		cfg: &Config{
-			Host:    defaultHost,
-			Format:  JsonFormat,
-			Lang:    Ru,
-			Version: apiVersion,
-		},
-		keys: keys,
+	if req.Event != "" && !req.Event.valid() {
+		return nil, validationError("unknown event %q", req.Event)
+	}
+	if req.System != "" && !req.System.valid() {
+		return nil, validationError("unknown system %q", req.System)
+	}
+	if req.ShowSystems != "" && !req.ShowSystems.validShow() {
+		return nil, validationError("unknown show_systems %q", req.ShowSystems)
 	}
-}
-
-type SchedulesRequest struct {
-	Station       string        //
-	Time          time.Time     //
-	TransportType TransportType //
-	Offset        int
-	Limit         int
-}
-
-type SchedulesResponse struct {
-	Pagination        Pagination  `json:"pagination"`         // Информация о постраничном выводе найденных рейсов.
-	Date              string      `json:"date"`               // Дата, на которую получен список рейсов.
-	Station           Station     `json:"station"`            // Информация об указанной в запросе станции.
-	Schedule          []Schedule  `json:"schedule"`           // Список рейсов.
-	ScheduleDirection interface{} `json:"schedule_direction"` // Код и название запрошенного направления рейсов.
-	Directions        interface{} `json:"directions"`         // Коды и названия возможных направлений движения электричек по станции.
-}
 
-func (c *client) Schedules(ctx context.Context, req SchedulesRequest) (*SchedulesResponse, error) {
 	u := url.URL{
-		Scheme: scheme,
+		Scheme: c.cfg.scheme(),
 		Host:   c.cfg.Host,
 		Path:   c.cfg.Version + "/schedule/",
 	}
 
 	q := u.Query()
-	q.Set("apikey", c.key())
 	q.Set("format", c.cfg.Format.String())
 	q.Set("lang", c.cfg.Lang.String())
-	q.Set("transport_type", req.TransportType.String())
 	q.Set("station", req.Station)
+	if req.TransportType != "" {
+		q.Set("transport_types", req.TransportType.String())
+	}
 	if !req.Time.IsZero() {
 		q.Set("date", req.Time.Format(dateFormat))
 	}
+	if req.Event != "" {
+		q.Set("event", req.Event.String())
+	}
+	if req.Direction != "" {
+		q.Set("direction", req.Direction)
+	}
+	if req.System != "" {
+		q.Set("system", req.System.String())
+	}
+	if req.ShowSystems != "" {
+		q.Set("show_systems", req.ShowSystems.String())
+	}
+	if req.ResultTimezone != nil {
+		tz, err := timezone(req.ResultTimezone)
+		if err != nil {
+			return nil, validationError("%v", err)
+		}
+		q.Set("result_timezone", tz)
+	}
 
 	if req.Offset != 0 {
 		q.Set("offset", strconv.Itoa(req.Offset))
@@ -142,7 +136,7 @@ func (c *client) Schedules(ctx context.Context, req SchedulesRequest) (*Schedule
 	u.RawQuery = q.Encode()
 
 	var resp SchedulesResponse
-	if err := c.get(ctx, u.String(), &resp); err != nil {
+	if err := c.get(ctx, req, u, &resp); err != nil {
 		return nil, err
 	}
 
@@ -150,25 +144,24 @@ func (c *client) Schedules(ctx context.Context, req SchedulesRequest) (*Schedule
 }
 
 type StationsListResponse struct {
-	Countries []Country `json:"countries"`
+	Countries []Country `json:"countries" xml:"countries>country"`
 }
 
 func (c *client) StationsList(ctx context.Context) (*StationsListResponse, error) {
 	u := url.URL{
-		Scheme: scheme,
+		Scheme: c.cfg.scheme(),
 		Host:   c.cfg.Host,
 		Path:   c.cfg.Version + "/stations_list/",
 	}
 
 	q := u.Query()
-	q.Set("apikey", c.key())
 	q.Set("format", c.cfg.Format.String())
 	q.Set("lang", c.cfg.Lang.String())
 
 	u.RawQuery = q.Encode()
 
 	var resp StationsListResponse
-	if err := c.get(ctx, u.String(), &resp); err != nil {
+	if err := c.get(ctx, nil, u, &resp); err != nil {
 		return nil, err
 	}
 
@@ -176,33 +169,54 @@ func (c *client) StationsList(ctx context.Context) (*StationsListResponse, error
 }
 
 type SearchRequest struct {
-	From   string
-	To     string
-	Date   time.Time
-	Offset int
-	Limit  int
+	From           string
+	To             string
+	Date           time.Time
+	TransportType  TransportType  // Тип транспорта, по умолчанию все типы
+	System         system         // Система кодирования для From и To, по умолчанию yandex
+	ShowSystems    system         // Система кодирования для кодов станций в ответе
+	ResultTimezone *time.Location // Часовой пояс для дат и времени в ответе, по умолчанию часовой пояс станций
+	AddDaysMask    bool           // Добавить в ответ календарь хождения рейсов
+	Transfers      bool           // Искать маршруты с пересадками
+	Offset         int
+	Limit          int
+}
+
+// Параметры выполненного поиска
+type SearchInfo struct {
+	Date string `json:"date" xml:"date"`
+	From Point  `json:"from" xml:"from"`
+	To   Point  `json:"to" xml:"to"`
 }
 
 type SearchResponse struct {
-	Pagination       Pagination    `json:"pagination"`
-	IntervalSegments []interface{} `json:"interval_segments"`
-	Segments         []Segment     `json:"segments"`
-	Search           interface{}   `json:"search"`
+	Pagination       Pagination `json:"pagination" xml:"pagination"`
+	IntervalSegments []Segment  `json:"interval_segments" xml:"interval_segments>segment"`
+	Segments         []Segment  `json:"segments" xml:"segments>segment"`
+	Search           SearchInfo `json:"search" xml:"search"`
 }
 
 func (c *client) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
 	if req.From == "" || req.To == "" {
-		return nil, errors.New("one of required request param are missing")
+		return nil, validationError("one of required request param are missing")
+	}
+	if req.TransportType != "" && !req.TransportType.valid() {
+		return nil, validationError("unknown transport type %q", req.TransportType)
+	}
+	if req.System != "" && !req.System.valid() {
+		return nil, validationError("unknown system %q", req.System)
+	}
+	if req.ShowSystems != "" && !req.ShowSystems.validShow() {
+		return nil, validationError("unknown show_systems %q", req.ShowSystems)
 	}
 
 	u := url.URL{
-		Scheme: scheme,
+		Scheme: c.cfg.scheme(),
 		Host:   c.cfg.Host,
 		Path:   c.cfg.Version + "/search/",
 	}
 
 	q := u.Query()
-	q.Set("apikey", c.key())
 	q.Set("format", c.cfg.Format.String())
 	q.Set("lang", c.cfg.Lang.String())
 	q.Set("from", req.From)
@@ -211,6 +225,28 @@ func (c *client) Search(ctx context.Context, req SearchRequest) (*SearchResponse
 	if !req.Date.IsZero() {
 		q.Set("date", req.Date.Format(dateFormat))
 	}
+	if req.TransportType != "" {
+		q.Set("transport_types", req.TransportType.String())
+	}
+	if req.System != "" {
+		q.Set("system", req.System.String())
+	}
+	if req.ShowSystems != "" {
+		q.Set("show_systems", req.ShowSystems.String())
+	}
+	if req.ResultTimezone != nil {
+		tz, err := timezone(req.ResultTimezone)
+		if err != nil {
+			return nil, validationError("%v", err)
+		}
+		q.Set("result_timezone", tz)
+	}
+	if req.AddDaysMask {
+		q.Set("add_days_mask", "true")
+	}
+	if req.Transfers {
+		q.Set("transfers", "true")
+	}
 
 	if req.Offset != 0 {
 		q.Set("offset", strconv.Itoa(req.Offset))
@@ -222,7 +258,7 @@ func (c *client) Search(ctx context.Context, req SearchRequest) (*SearchResponse
 	u.RawQuery = q.Encode()
 
 	var resp SearchResponse
-	if err := c.get(ctx, u.String(), &resp); err != nil {
+	if err := c.get(ctx, req, u, &resp); err != nil {
 		return nil, err
 	}
 
@@ -230,40 +266,64 @@ func (c *client) Search(ctx context.Context, req SearchRequest) (*SearchResponse
 }
 
 type ThreadRequest struct {
-	UID  string
-	From string
-	To   string
+	UID         string
+	From        string
+	To          string
+	Date        time.Time // Дата, на которую нужен список станций следования
+	ShowSystems system    // Система кодирования для кодов станций в ответе
 }
 
 type ThreadResponse struct {
-	Days      string     `json:"days"`
-	Stops     []Stop     `json:"stops"`
-	Transport *Transport `json:"transport_subtype"`
+	UID           string        `json:"uid" xml:"uid"`                             // Идентификатор нитки.
+	Title         string        `json:"title" xml:"title"`                         // Название нитки.
+	Number        string        `json:"number" xml:"number"`                       // Номер рейса.
+	ShortTitle    string        `json:"short_title" xml:"short_title"`             // Короткое название нитки.
+	Carrier       Carrier       `json:"carrier" xml:"carrier"`                     // Перевозчик.
+	Vehicle       string        `json:"vehicle" xml:"vehicle"`                     // Название транспортного средства.
+	TransportType TransportType `json:"transport_type" xml:"transport_type"`       // Тип транспорта.
+	StartTime     string        `json:"start_time" xml:"start_time"`               // Время отправления с начальной станции нитки.
+	StartDate     string        `json:"start_date" xml:"start_date"`               // Дата отправления с начальной станции нитки.
+	Days          string        `json:"days" xml:"days"`                           // Дни курсирования нитки.
+	ExceptDays    string        `json:"except_days" xml:"except_days"`             // Дни, в которые нитка не курсирует.
+	Stops         []Stop        `json:"stops" xml:"stops>stop"`                    // Станции следования.
+	Transport     *Transport    `json:"transport_subtype" xml:"transport_subtype"` // Подтип транспорта.
 }
 
 func (c *client) Thread(ctx context.Context, req ThreadRequest) (*ThreadResponse, error) {
 	if req.UID == "" {
-		return nil, errors.New("uid are missing")
+		return nil, validationError("uid are missing")
+	}
+	if req.ShowSystems != "" && !req.ShowSystems.validShow() {
+		return nil, validationError("unknown show_systems %q", req.ShowSystems)
 	}
 
 	u := url.URL{
-		Scheme: scheme,
+		Scheme: c.cfg.scheme(),
 		Host:   c.cfg.Host,
 		Path:   c.cfg.Version + "/thread/",
 	}
 
 	q := u.Query()
-	q.Set("apikey", c.key())
 	q.Set("format", c.cfg.Format.String())
 	q.Set("lang", c.cfg.Lang.String())
 	q.Set("uid", req.UID)
-	q.Set("from", req.From)
-	q.Set("to", req.To)
+	if req.From != "" {
+		q.Set("from", req.From)
+	}
+	if req.To != "" {
+		q.Set("to", req.To)
+	}
+	if !req.Date.IsZero() {
+		q.Set("date", req.Date.Format(dateFormat))
+	}
+	if req.ShowSystems != "" {
+		q.Set("show_systems", req.ShowSystems.String())
+	}
 
 	u.RawQuery = q.Encode()
 
 	var resp ThreadResponse
-	if err := c.get(ctx, u.String(), &resp); err != nil {
+	if err := c.get(ctx, req, u, &resp); err != nil {
 		return nil, err
 	}
 
@@ -271,44 +331,35 @@ func (c *client) Thread(ctx context.Context, req ThreadRequest) (*ThreadResponse
 }
 
 type NearestStationsRequest struct {
-	Lat         float64
-	Lng         float64
-	StationType string
-	Distance    int
-	Offset      int
-	Limit       int
+	Lat           float64
+	Lng           float64
+	StationType   string
+	TransportType TransportType // Тип транспорта станций, по умолчанию все типы
+	Distance      int
+	Offset        int
+	Limit         int
 }
 
 type NearestStationsResponse struct {
-	Pagination Pagination `json:"pagination"`
-	Stations   []struct {
-		Distance        float64       `json:"distance"`
-		Code            string        `json:"code"`
-		StationType     string        `json:"station_type"`
-		TypeChoices     interface{}   `json:"type_choices"`
-		Title           string        `json:"title"`
-		TransportType   TransportType `json:"transport_type"`
-		Lat             float64       `json:"lat"`
-		Lng             float64       `json:"lng"`
-		Type            string        `json:"type"`
-		StationTypeName string        `json:"station_type_name"`
-		Majority        int           `json:"majority"`
-	} `json:"stations"`
+	Pagination Pagination       `json:"pagination" xml:"pagination"`
+	Stations   []NearestStation `json:"stations" xml:"stations>station"`
 }
 
 func (c *client) NearestStations(ctx context.Context, req NearestStationsRequest) (*NearestStationsResponse, error) {
 	if req.Lat == 0 || req.Lng == 0 {
-		return nil, fmt.Errorf("unable to require params")
+		return nil, validationError("unable to require params")
+	}
+	if req.TransportType != "" && !req.TransportType.valid() {
+		return nil, validationError("unknown transport type %q", req.TransportType)
 	}
 
 	u := url.URL{
-		Scheme: scheme,
+		Scheme: c.cfg.scheme(),
 		Host:   c.cfg.Host,
 		Path:   c.cfg.Version + "/nearest_stations/",
 	}
 
 	q := u.Query()
-	q.Set("apikey", c.key())
 	q.Set("format", c.cfg.Format.String())
 	q.Set("lang", c.cfg.Lang.String())
 	q.Set("lat", strconv.FormatFloat(req.Lat, 'f', -1, 64))
@@ -319,6 +370,9 @@ func (c *client) NearestStations(ctx context.Context, req NearestStationsRequest
 	if req.StationType != "" {
 		q.Set("station_types", req.StationType)
 	}
+	if req.TransportType != "" {
+		q.Set("transport_types", req.TransportType.String())
+	}
 
 	if req.Offset != 0 {
 		q.Set("offset", strconv.Itoa(req.Offset))
@@ -330,7 +384,7 @@ func (c *client) NearestStations(ctx context.Context, req NearestStationsRequest
 	u.RawQuery = q.Encode()
 
 	var resp NearestStationsResponse
-	if err := c.get(ctx, u.String(), &resp); err != nil {
+	if err := c.get(ctx, req, u, &resp); err != nil {
 		return nil, err
 	}
 
@@ -346,29 +400,28 @@ type NearestCityRequest struct {
 }
 
 type NearestCityResponse struct {
-	Distance     float64 `json:"distance"`
-	Code         string  `json:"code"`
-	Title        string  `json:"title"`
-	PopularTitle string  `json:"popular_title"`
-	ShortTitle   string  `json:"short_title"`
-	Lat          float64 `json:"lat"`
-	Lng          float64 `json:"lng"`
-	Type         string  `json:"type"`
+	Distance     float64 `json:"distance" xml:"distance"`
+	Code         string  `json:"code" xml:"code"`
+	Title        string  `json:"title" xml:"title"`
+	PopularTitle string  `json:"popular_title" xml:"popular_title"`
+	ShortTitle   string  `json:"short_title" xml:"short_title"`
+	Lat          float64 `json:"lat" xml:"lat"`
+	Lng          float64 `json:"lng" xml:"lng"`
+	Type         string  `json:"type" xml:"type"`
 }
 
 func (c *client) NearestCity(ctx context.Context, req NearestCityRequest) (*NearestCityResponse, error) {
 	if req.Lat == 0 || req.Lng == 0 {
-		return nil, fmt.Errorf("unable to require params")
+		return nil, validationError("unable to require params")
 	}
 
 	u := url.URL{
-		Scheme: scheme,
+		Scheme: c.cfg.scheme(),
 		Host:   c.cfg.Host,
 		Path:   c.cfg.Version + "/nearest_settlement/",
 	}
 
 	q := u.Query()
-	q.Set("apikey", c.key())
 	q.Set("format", c.cfg.Format.String())
 	q.Set("lang", c.cfg.Lang.String())
 	q.Set("lat", strconv.FormatFloat(req.Lat, 'f', -1, 64))
@@ -387,60 +440,305 @@ func (c *client) NearestCity(ctx context.Context, req NearestCityRequest) (*Near
 	u.RawQuery = q.Encode()
 
 	var resp NearestCityResponse
-	if err := c.get(ctx, u.String(), &resp); err != nil {
+	if err := c.get(ctx, req, u, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+type CarrierRequest struct {
+	Code   string // Код перевозчика
+	System system // Система кодирования, по умолчанию yandex
+}
+
+type CarrierResponse struct {
+	Carrier  Carrier   `json:"carrier" xml:"carrier"`           // Информация о перевозчике.
+	Carriers []Carrier `json:"carriers" xml:"carriers>carrier"` // Все перевозчики с указанным кодом (для кодов IATA код может принадлежать нескольким перевозчикам).
+}
+
+func (c *client) Carrier(ctx context.Context, req CarrierRequest) (*CarrierResponse, error) {
+	if req.Code == "" {
+		return nil, validationError("code are missing")
+	}
+	if req.System != "" && !req.System.valid() {
+		return nil, validationError("unknown system %q", req.System)
+	}
+
+	u := url.URL{
+		Scheme: c.cfg.scheme(),
+		Host:   c.cfg.Host,
+		Path:   c.cfg.Version + "/carrier/",
+	}
+
+	q := u.Query()
+	q.Set("format", c.cfg.Format.String())
+	q.Set("lang", c.cfg.Lang.String())
+	q.Set("code", req.Code)
+	if req.System != "" {
+		q.Set("system", req.System.String())
+	}
+
+	u.RawQuery = q.Encode()
+
+	var resp CarrierResponse
+	if err := c.get(ctx, req, u, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+type CopyrightResponse struct {
+	Copyright Copyright `json:"copyright" xml:"copyright"`
+}
+
+func (c *client) Copyright(ctx context.Context) (*CopyrightResponse, error) {
+	u := url.URL{
+		Scheme: c.cfg.scheme(),
+		Host:   c.cfg.Host,
+		Path:   c.cfg.Version + "/copyright/",
+	}
+
+	q := u.Query()
+	q.Set("format", c.cfg.Format.String())
+
+	u.RawQuery = q.Encode()
+
+	var resp CopyrightResponse
+	if err := c.get(ctx, nil, u, &resp); err != nil {
 		return nil, err
 	}
 
 	return &resp, nil
 }
 
-func (c *client) get(ctx context.Context, url string, resp interface{}) error {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// get выполняет вызов метода API через цепочку middleware и разбирает ответ в resp
+func (c *client) get(ctx context.Context, req interface{}, u url.URL, resp interface{}) error {
+	return c.call(ctx, &Call{Endpoint: endpoint(u), Request: req, URL: u, Result: resp}, func(ctx context.Context, call *Call) error {
+		return c.cached(ctx, call.URL, call.Result)
+	})
+}
+
+// call выполняет вызов через цепочку middleware, последним звеном которой выполняется run
+func (c *client) call(ctx context.Context, call *Call, run DoerFunc) error {
+	call.run = run
+	if c.doer == nil {
+		return c.exec(ctx, call)
+	}
+	return c.doer.Do(ctx, call)
+}
+
+// exec последнее звено цепочки middleware
+func (c *client) exec(ctx context.Context, call *Call) error {
+	start := time.Now()
+	err := call.run(context.WithValue(ctx, callKey{}, call), call)
+	call.Latency = time.Since(start)
+	if slow := c.log.slowRequest(); slow > 0 && call.Latency >= slow {
+		c.log.log(ctx, EventSlowRequest, "slow request",
+			Attribute{Key: "endpoint", Value: call.Endpoint},
+			Attribute{Key: "latency", Value: call.Latency},
+			Attribute{Key: "attempts", Value: len(call.Attempts)},
+		)
+	}
+	return c.pool.redactError(err)
+}
+
+// cached отдает ответ из cfg.Cache, если он есть, и сохраняет в кеш новые ответы
+func (c *client) cached(ctx context.Context, u url.URL, resp interface{}) error {
+	ttl := c.cfg.cacheTTL(endpoint(u))
+	if c.cfg.Cache == nil || ttl <= 0 || cacheBypassed(ctx) {
+		body, err := c.fetch(ctx, u)
+		if err != nil {
+			return err
+		}
+		return c.decodeBody(ctx, u, body, resp)
+	}
+
+	key := u.String()
+	if body, ok := c.cfg.Cache.Get(key); ok {
+		if err := c.decodeBody(ctx, u, body, resp); err == nil {
+			if call := callFrom(ctx); call != nil {
+				call.Cached = true
+			}
+			c.log.log(ctx, EventCacheHit, "cache hit", Attribute{Key: "endpoint", Value: endpoint(u)}, Attribute{Key: "url", Value: key})
+			return nil
+		}
+	}
+	c.log.log(ctx, EventCacheMiss, "cache miss", Attribute{Key: "endpoint", Value: endpoint(u)}, Attribute{Key: "url", Value: key})
+
+	body, err := c.fetch(ctx, u)
 	if err != nil {
 		return err
 	}
+	if err := c.decodeBody(ctx, u, body, resp); err != nil {
+		return err
+	}
+	c.cfg.Cache.Set(key, body, ttl)
 
-	httpResp, err := c.client.Do(req.WithContext(ctx))
+	return nil
+}
+
+// decodeBody разбирает тело ответа на запрос u, сообщая об ошибке в журнал
+func (c *client) decodeBody(ctx context.Context, u url.URL, body []byte, resp interface{}) error {
+	err := c.decode(body, resp)
 	if err != nil {
-		return err
+		c.log.log(ctx, EventDecodeError, "decode response",
+			Attribute{Key: "endpoint", Value: endpoint(u)},
+			Attribute{Key: "format", Value: c.cfg.Format.String()},
+			Attribute{Key: "size", Value: len(body)},
+			Attribute{Key: "error", Value: err},
+		)
+	}
+	return err
+}
+
+// fetch выполняет запрос и читает тело ответа целиком
+func (c *client) fetch(ctx context.Context, u url.URL) ([]byte, error) {
+	body, err := c.retry(ctx, u)
+	if err != nil {
+		return nil, err
 	}
-	defer httpResp.Body.Close()
+	defer body.Close()
 
-	if httpResp.StatusCode != http.StatusOK {
-		if httpResp.StatusCode == http.StatusTooManyRequests {
-			err := c.nextKey()
-			if err != nil {
-				return err
+	return ioutil.ReadAll(body)
+}
+
+// retry выполняет запрос, повторяя его согласно cfg.Retry, и возвращает тело успешного ответа
+func (c *client) retry(ctx context.Context, u url.URL) (io.ReadCloser, error) {
+	policy := c.cfg.Retry.withDefaults()
+
+	var lastErr error
+	for attempt, rotations := 1, 0; ; {
+		key, idx, err := c.pool.acquire()
+		if err != nil {
+			if lastErr != nil {
+				return nil, lastErr
 			}
+			return nil, err
 		}
+
+		status, body, err := c.do(ctx, u, key, idx)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		switch {
+		case status == http.StatusTooManyRequests:
+			c.pool.limited(idx, err)
+			// Каждый ключ пула получает не больше одной попытки после ответа 429
+			rotations++
+			if rotations >= len(c.pool.keys) || ctx.Err() != nil {
+				return nil, err
+			}
+			c.log.log(ctx, EventKeyRotation, "api key rate limited, switching key",
+				Attribute{Key: "endpoint", Value: endpoint(u)},
+				Attribute{Key: "key_index", Value: idx},
+				Attribute{Key: "key", Value: maskKey(key)},
+			)
+		case status == 0 && ctx.Err() != nil:
+			return nil, err
+		case status == 0, status >= http.StatusInternalServerError:
+			if attempt >= policy.MaxAttempts {
+				return nil, err
+			}
+			delay := policy.backoff(attempt)
+			c.log.log(ctx, EventRetry, "retry request",
+				Attribute{Key: "endpoint", Value: endpoint(u)},
+				Attribute{Key: "attempt", Value: attempt},
+				Attribute{Key: "status", Value: status},
+				Attribute{Key: "delay", Value: delay},
+				Attribute{Key: "error", Value: err},
+			)
+			if !sleep(ctx, delay) {
+				return nil, err
+			}
+			attempt++
+		default:
+			return nil, err
+		}
+	}
+}
+
+// do выполняет один запрос с ключом key под номером idx в пуле. Возвращает код ответа или 0, если ответ не получен.
+// Тело успешного ответа закрывает вызывающий.
+func (c *client) do(ctx context.Context, u url.URL, key string, idx int) (int, io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	// Ключ передается в заголовке, чтобы он не попадал в URL, ошибки и логи
+	req.Header.Set("Authorization", key)
+	if c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	if span := spanFrom(ctx); span != nil {
+		if sc := span.SpanContext(); sc.Valid() {
+			req.Header.Set("traceparent", sc.TraceParent())
+		}
+	}
+
+	start := time.Now()
+	httpResp, err := c.client.Do(req.WithContext(ctx))
+	status, body, err := c.response(u, httpResp, err)
+	if call := callFrom(ctx); call != nil {
+		masked := maskRequest(req, key)
+		var maskedResp *http.Response
+		if httpResp != nil {
+			// Ответ ссылается на исходный запрос с ключом, middleware получает копию с маской
+			r := *httpResp
+			r.Request = masked
+			maskedResp = &r
+		}
+		call.Attempts = append(call.Attempts, Attempt{
+			Request:  masked,
+			Response: maskedResp,
+			Err:      c.pool.redactError(err),
+			KeyIndex: idx,
+			Latency:  time.Since(start),
+		})
+	}
+
+	return status, body, err
+}
+
+// response возвращает тело успешного ответа, иначе закрывает ответ и возвращает ошибку
+func (c *client) response(u url.URL, httpResp *http.Response, err error) (int, io.ReadCloser, error) {
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+
 		body, err := ioutil.ReadAll(httpResp.Body)
 		if err != nil {
-			return err
+			return 0, nil, err
 		}
-
-		return fmt.Errorf("%d status code: %s", httpResp.StatusCode, string(body))
+		return httpResp.StatusCode, nil, newAPIError(endpoint(u), httpResp.StatusCode, c.cfg.Format, body)
 	}
 
+	return httpResp.StatusCode, httpResp.Body, nil
+}
+
+// decode разбирает тело ответа в формате cfg.Format
+func (c *client) decode(body []byte, resp interface{}) error {
+	return c.decodeReader(bytes.NewReader(body), resp)
+}
+
+func (c *client) decodeReader(r io.Reader, resp interface{}) error {
 	switch c.cfg.Format {
 	case JsonFormat:
-		return json.NewDecoder(httpResp.Body).Decode(resp)
+		return json.NewDecoder(r).Decode(resp)
 	case XmlFormat:
-		return xml.NewDecoder(httpResp.Body).Decode(resp)
+		return xml.NewDecoder(r).Decode(resp)
 	default:
 		return errors.New("format unsupported")
 	}
 }
 
-func (c *client) key() string {
-	return c.keys[c.idxKey]
-}
-
-func (c *client) nextKey() error {
-	if c.idxKey == len(c.keys)-1 {
-		return errors.New("pool api keys is empty")
-	}
-
-	c.idxKey = c.idxKey + 1
-
-	return nil
+// endpoint возвращает название метода API по пути запроса, например «search»
+func endpoint(u url.URL) string {
+	return path.Base(u.Path)
 }
@@ -3,13 +3,60 @@ package yandex
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestClient возвращает клиент, запросы которого обрабатывает fn
+func newTestClient(fn roundTripFunc) *client {
+	return &client{
+		client: &http.Client{Transport: fn},
+		cfg: &config{
+			Host:    defaultHost,
+			Format:  JsonFormat,
+			Lang:    Ru,
+			Version: apiVersion,
+		},
+		pool: NewKeyPool([]string{"test-key"}, KeyPoolConfig{}),
+	}
+}
+
+// newLiveClient возвращает клиент реального API с ключом из переменной окружения YANDEX_RASP_API_KEY.
+// Без ключа тест пропускается.
+func newLiveClient(t *testing.T) Client {
+	key := os.Getenv("YANDEX_RASP_API_KEY")
+	if key == "" {
+		t.Skip("YANDEX_RASP_API_KEY is not set")
+	}
+	client, err := New(WithKeys(key))
+	require.NoError(t, err)
+	return client
+}
+
+// okResponse возвращает успешный ответ с телом body
+func okResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
 func TestClient_Search(t *testing.T) {
-	t.Skip()
-	client := NewWithDefaultConfig("") // set api key
+	client := newLiveClient(t)
 	resp, err := client.Search(context.TODO(), SearchRequest{
 		From: "s2006004",
 		To:   "s9602494",
@@ -24,9 +71,59 @@ func TestClient_Search(t *testing.T) {
 	}
 }
 
+func TestClient_SearchParams(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Yekaterinburg")
+	require.NoError(t, err)
+
+	var got *http.Request
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		got = req
+		return okResponse(`{}`), nil
+	})
+
+	_, err = c.Search(context.TODO(), SearchRequest{
+		From:           "c213",
+		To:             "c54",
+		Date:           time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC),
+		TransportType:  Train,
+		System:         YandexSystem,
+		ShowSystems:    AllSystems,
+		ResultTimezone: loc,
+		AddDaysMask:    true,
+	})
+	require.NoError(t, err)
+
+	q := got.URL.Query()
+	assert.Equal(t, "train", q.Get("transport_types"))
+	assert.Equal(t, "yandex", q.Get("system"))
+	assert.Equal(t, "all", q.Get("show_systems"))
+	assert.Equal(t, "Asia/Yekaterinburg", q.Get("result_timezone"))
+	assert.Equal(t, "true", q.Get("add_days_mask"))
+	assert.Equal(t, "2020-01-10", q.Get("date"))
+	assert.Empty(t, q.Get("transfers"))
+}
+
+func TestClient_SearchValidation(t *testing.T) {
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("unexpected request")
+		return nil, nil
+	})
+
+	for _, req := range []SearchRequest{
+		{From: "c213"},
+		{From: "c213", To: "c54", TransportType: "car"},
+		{From: "c213", To: "c54", System: AllSystems},
+		{From: "c213", To: "c54", ShowSystems: IataSystem},
+		{From: "c213", To: "c54", ResultTimezone: time.Local},
+		{From: "c213", To: "c54", ResultTimezone: time.FixedZone("MSK", 3*60*60)},
+	} {
+		_, err := c.Search(context.TODO(), req)
+		assert.Error(t, err)
+	}
+}
+
 func TestClient_Schedules(t *testing.T) {
-	t.Skip()
-	client := NewWithDefaultConfig("") // set api key
+	client := newLiveClient(t)
 	resp, err := client.Schedules(context.TODO(), SchedulesRequest{
 		Station:       "s2006004",
 		Time:          time.Now(),
@@ -41,9 +138,44 @@ func TestClient_Schedules(t *testing.T) {
 	}
 }
 
+func TestClient_SchedulesParams(t *testing.T) {
+	var got *http.Request
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		got = req
+		return okResponse(`{
+			"schedule_direction": {"code": "на Москву", "title": "на Москву"},
+			"directions": [{"code": "all", "title": "все"}, {"code": "на Москву", "title": "на Москву"}]
+		}`), nil
+	})
+
+	resp, err := c.Schedules(context.TODO(), SchedulesRequest{
+		Station:        "s9600213",
+		TransportType:  Suburban,
+		Event:          ArrivalEvent,
+		Direction:      "на Москву",
+		System:         EsrSystem,
+		ShowSystems:    EsrSystem,
+		ResultTimezone: time.UTC,
+	})
+	require.NoError(t, err)
+
+	q := got.URL.Query()
+	assert.Equal(t, "suburban", q.Get("transport_types"))
+	assert.Equal(t, "arrival", q.Get("event"))
+	assert.Equal(t, "на Москву", q.Get("direction"))
+	assert.Equal(t, "esr", q.Get("system"))
+	assert.Equal(t, "esr", q.Get("show_systems"))
+	assert.Equal(t, "UTC", q.Get("result_timezone"))
+
+	assert.Equal(t, Direction{Code: "на Москву", Title: "на Москву"}, resp.ScheduleDirection)
+	assert.Len(t, resp.Directions, 2)
+
+	_, err = c.Schedules(context.TODO(), SchedulesRequest{Station: "s9600213", Event: "stop"})
+	assert.Error(t, err)
+}
+
 func TestClient_StationsList(t *testing.T) {
-	t.Skip()
-	client := NewWithDefaultConfig("") // set api key
+	client := newLiveClient(t)
 	resp, err := client.StationsList(context.TODO())
 	if err != nil {
 		t.Error(err)
@@ -55,8 +187,7 @@ func TestClient_StationsList(t *testing.T) {
 }
 
 func TestClient_Thread(t *testing.T) {
-	t.Skip()
-	client := NewWithDefaultConfig("") // set api key
+	client := newLiveClient(t)
 	resp, err := client.Thread(context.TODO(), ThreadRequest{
 		UID: "726CH_1_2",
 	})
@@ -70,9 +201,52 @@ func TestClient_Thread(t *testing.T) {
 	}
 }
 
+func TestClient_ThreadResponse(t *testing.T) {
+	var got *http.Request
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		got = req
+		return okResponse(`{
+			"uid": "726CH_1_2",
+			"title": "Москва — Екатеринбург",
+			"number": "726Ч",
+			"short_title": "Москва — Екатеринбург",
+			"carrier": {"code": 112, "title": "РЖД/ФПК"},
+			"vehicle": "Ласточка",
+			"transport_type": "train",
+			"start_time": "23:40",
+			"start_date": "2020-01-10",
+			"days": "ежедневно",
+			"except_days": "",
+			"stops": [{"station": {"code": "s2000001"}}, {"station": {"code": "s9607404"}}],
+			"transport_subtype": {"code": "last", "title": "Ласточка"}
+		}`), nil
+	})
+
+	resp, err := c.Thread(context.TODO(), ThreadRequest{
+		UID:         "726CH_1_2",
+		Date:        time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC),
+		ShowSystems: AllSystems,
+	})
+	require.NoError(t, err)
+
+	q := got.URL.Query()
+	assert.Equal(t, "2020-01-10", q.Get("date"))
+	assert.Equal(t, "all", q.Get("show_systems"))
+	_, hasFrom := q["from"]
+	assert.False(t, hasFrom)
+
+	assert.Equal(t, "726CH_1_2", resp.UID)
+	assert.Equal(t, "726Ч", resp.Number)
+	assert.Equal(t, 112, resp.Carrier.Code)
+	assert.Equal(t, Train, resp.TransportType)
+	assert.Equal(t, "23:40", resp.StartTime)
+	assert.Equal(t, "2020-01-10", resp.StartDate)
+	assert.Len(t, resp.Stops, 2)
+	assert.Equal(t, "last", resp.Transport.Code)
+}
+
 func TestClient_NearestStations(t *testing.T) {
-	t.Skip()
-	client := NewWithDefaultConfig("") // set api key
+	client := newLiveClient(t)
 	resp, err := client.NearestStations(context.TODO(), NearestStationsRequest{
 		Lat:      57.47609910000001,
 		Lng:      60.2535071,
@@ -86,3 +260,30 @@ func TestClient_NearestStations(t *testing.T) {
 		t.Error("empty stations")
 	}
 }
+
+func TestClient_Carrier(t *testing.T) {
+	client := newLiveClient(t)
+	resp, err := client.Carrier(context.TODO(), CarrierRequest{
+		Code:   "SU",
+		System: IataSystem,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(resp.Carriers) == 0 && resp.Carrier.Code == 0 {
+		t.Error("empty carrier")
+	}
+}
+
+func TestClient_Copyright(t *testing.T) {
+	client := newLiveClient(t)
+	resp, err := client.Copyright(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+
+	if resp.Copyright.Text == "" {
+		t.Error("empty copyright")
+	}
+}
@@ -0,0 +1,90 @@
+package yandex
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Codes коды объекта в системах кодирования, например yandex_code и esr_code
+type Codes map[string]string
+
+func (c *Codes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*c = make(Codes)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var v string
+			if err := d.DecodeElement(&v, &t); err != nil {
+				return err
+			}
+			(*c)[t.Name.Local] = v
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (c Codes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := e.EncodeElement(c[k], xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// Coordinate широта или долгота. В списке станций API отдает пустую строку, если координаты неизвестны.
+type Coordinate float64
+
+func (c *Coordinate) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return c.parse(s)
+	}
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*c = Coordinate(f)
+	return nil
+}
+
+func (c *Coordinate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	return c.parse(s)
+}
+
+func (c *Coordinate) parse(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*c = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*c = Coordinate(f)
+	return nil
+}
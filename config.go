@@ -1,11 +1,32 @@
 package yandex
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
 
-type Config struct {
+// config настройки клиента, которые задают опции New
+type config struct {
+	Scheme  string // Схема запросов, по умолчанию https. Для тестового сервера yarasptest — http
 	Host    string
 	Format  format
 	Lang    lang
 	Version string
 	Timeout time.Duration
+
+	UserAgent string // Заголовок User-Agent запросов
+
+	Transport http.RoundTripper // Транспорт HTTP запросов, nil — http.Transport с таймаутами соединения
+	KeyPool   *KeyPool          // Пул API ключей
+	Retry     RetryPolicy       // Политика повтора запросов, по умолчанию DefaultRetryPolicy
+
+	Cache    Cache                    // Кеш ответов, nil — без кеша
+	CacheTTL map[string]time.Duration // Время жизни ответов по методам API, дополняет и переопределяет DefaultCacheTTL
+}
+
+func (c *config) scheme() string {
+	if c.Scheme == "" {
+		return defaultScheme
+	}
+	return c.Scheme
 }
@@ -0,0 +1,64 @@
+package yandex
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// Копирайт Яндекс Расписаний, который необходимо показывать вместе с данными API
+type Copyright struct {
+	URL    string `json:"url" xml:"url"`         // Ссылка на Яндекс Расписания
+	Text   string `json:"text" xml:"text"`       // Текст копирайта
+	LogoVM string `json:"logo_vm" xml:"logo_vm"` // Вертикальный монохромный логотип
+	LogoVD string `json:"logo_vd" xml:"logo_vd"` // Вертикальный логотип для темного фона
+	LogoVY string `json:"logo_vy" xml:"logo_vy"` // Вертикальный цветной логотип
+	LogoHM string `json:"logo_hm" xml:"logo_hm"` // Горизонтальный монохромный логотип
+	LogoHD string `json:"logo_hd" xml:"logo_hd"` // Горизонтальный логотип для темного фона
+	LogoHY string `json:"logo_hy" xml:"logo_hy"` // Горизонтальный цветной логотип
+}
+
+// Logo возвращает ссылку на горизонтальный цветной логотип или первый доступный
+func (c Copyright) Logo() string {
+	for _, logo := range []string{c.LogoHY, c.LogoHM, c.LogoHD, c.LogoVY, c.LogoVM, c.LogoVD} {
+		if logo != "" {
+			return logo
+		}
+	}
+	return ""
+}
+
+// HTML возвращает копирайт в виде ссылки с логотипом.
+// Ссылки со схемой, отличной от http и https, не выводятся.
+func (c Copyright) HTML() string {
+	text := html.EscapeString(c.Text)
+	if logo := c.Logo(); safeURL(logo) {
+		text = fmt.Sprintf(`<img src="%s" alt="%s"> %s`, html.EscapeString(logo), text, text)
+	}
+	if !safeURL(c.URL) {
+		return text
+	}
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(c.URL), text)
+}
+
+// safeURL проверяет, что ссылка абсолютная и ведет на http или https
+func safeURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "http" || scheme == "https"
+}
+
+// PlainText возвращает копирайт в виде текста со ссылкой
+func (c Copyright) PlainText() string {
+	if c.URL == "" {
+		return c.Text
+	}
+	if c.Text == "" {
+		return c.URL
+	}
+	return c.Text + " (" + c.URL + ")"
+}
@@ -0,0 +1,32 @@
+package yandex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyright_HTML(t *testing.T) {
+	c := Copyright{
+		URL:    "http://rasp.yandex.ru/",
+		Text:   "Данные предоставлены сервисом Яндекс.Расписания",
+		LogoHY: "https://yastatic.net/rasp/logo_hy.svg",
+	}
+
+	assert.Equal(t, `<a href="http://rasp.yandex.ru/"><img src="https://yastatic.net/rasp/logo_hy.svg" alt="Данные предоставлены сервисом Яндекс.Расписания"> Данные предоставлены сервисом Яндекс.Расписания</a>`, c.HTML())
+	assert.Equal(t, "Данные предоставлены сервисом Яндекс.Расписания (http://rasp.yandex.ru/)", c.PlainText())
+}
+
+func TestCopyright_HTMLEscape(t *testing.T) {
+	c := Copyright{URL: `http://a/?b="c"`, Text: "<b>"}
+
+	assert.Equal(t, `<a href="http://a/?b=&#34;c&#34;">&lt;b&gt;</a>`, c.HTML())
+}
+
+func TestCopyright_HTMLUnsafeURL(t *testing.T) {
+	c := Copyright{URL: "javascript:alert(1)", Text: "text", LogoHY: "data:image/svg+xml,<svg/>"}
+	assert.Equal(t, "text", c.HTML())
+
+	c = Copyright{URL: " JavaScript://rasp.yandex.ru/%0Aalert(1)", Text: "text"}
+	assert.Equal(t, "text", c.HTML())
+}
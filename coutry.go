@@ -1,6 +1,8 @@
 package yandex
 
+// Country страна. Заголовок идет в JSON до списка регионов, что нужно для разбора StationsListStream.
 type Country struct {
-	Regions []Region `json:"regions"`
-	Name    string   `json:"title"`
+	Name    string   `json:"title" xml:"title"`
+	Codes   Codes    `json:"codes" xml:"codes"`
+	Regions []Region `json:"regions" xml:"regions>region"`
 }
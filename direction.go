@@ -0,0 +1,7 @@
+package yandex
+
+// Направление движения электричек по станции
+type Direction struct {
+	Code  string `json:"code" xml:"code"`   // Код направления, например «arrival» или «на Москву»
+	Title string `json:"title" xml:"title"` // Название направления
+}
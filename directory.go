@@ -0,0 +1,209 @@
+package yandex
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// DirectoryStation станция справочника вместе с населенным пунктом, регионом и страной.
+// Settlement, Region и Country хранятся без вложенных списков.
+type DirectoryStation struct {
+	Station
+	Settlement Settlement
+	Region     Region
+	Country    Country
+}
+
+// StationDirectory справочник станций из ответа stations_list для поиска без обращения к API.
+// После создания справочник не изменяется и безопасен для конкурентного использования.
+type StationDirectory struct {
+	stations          []DirectoryStation
+	byCode            map[string]int            // yandex_code
+	bySystem          map[string]map[string]int // esr_code, express_code и т.д.
+	byTitle           map[string][]int
+	bySettlement      map[string][]int // yandex_code населенного пункта
+	bySettlementTitle map[string][]int
+}
+
+// NewStationDirectory строит справочник по списку станций
+func NewStationDirectory(resp *StationsListResponse) *StationDirectory {
+	d := newStationDirectory()
+	walkStations(resp, StationsFilter{}, d.add)
+	return d
+}
+
+// LoadStationDirectory строит справочник по снимку списка станций в формате JSON,
+// например по сохраненному ответу stations_list или результату Save
+func LoadStationDirectory(r io.Reader) (*StationDirectory, error) {
+	d := newStationDirectory()
+	if err := newStationsWalker(r, StationsFilter{}, d.add).walk(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func newStationDirectory() *StationDirectory {
+	return &StationDirectory{
+		byCode:            make(map[string]int),
+		bySystem:          make(map[string]map[string]int),
+		byTitle:           make(map[string][]int),
+		bySettlement:      make(map[string][]int),
+		bySettlementTitle: make(map[string][]int),
+	}
+}
+
+func (d *StationDirectory) add(country Country, region Region, settlement Settlement, station Station) error {
+	idx := len(d.stations)
+	if code, ok := station.ExternalID(); ok && station.Code == "" {
+		station.Code = code
+	}
+	station.Region = region.Name
+	station.City = settlement.Name
+	d.stations = append(d.stations, DirectoryStation{
+		Station:    station,
+		Settlement: settlement,
+		Region:     region,
+		Country:    country,
+	})
+
+	if station.Code != "" {
+		d.byCode[station.Code] = idx
+	}
+	for system, code := range station.Codes {
+		if code == "" || system == "yandex_code" {
+			continue
+		}
+		if d.bySystem[system] == nil {
+			d.bySystem[system] = make(map[string]int)
+		}
+		d.bySystem[system][code] = idx
+	}
+	if station.Title != "" {
+		title := normalizeTitle(station.Title)
+		d.byTitle[title] = append(d.byTitle[title], idx)
+	}
+	if code := settlement.Codes["yandex_code"]; code != "" {
+		d.bySettlement[code] = append(d.bySettlement[code], idx)
+	}
+	if settlement.Name != "" {
+		title := normalizeTitle(settlement.Name)
+		d.bySettlementTitle[title] = append(d.bySettlementTitle[title], idx)
+	}
+	return nil
+}
+
+// Len возвращает число станций справочника
+func (d *StationDirectory) Len() int {
+	return len(d.stations)
+}
+
+// Stations возвращает все станции справочника в порядке списка
+func (d *StationDirectory) Stations() []DirectoryStation {
+	return append([]DirectoryStation(nil), d.stations...)
+}
+
+// ByCode ищет станцию по коду Яндекс Расписаний, например «s9600213»
+func (d *StationDirectory) ByCode(code string) (DirectoryStation, bool) {
+	idx, ok := d.byCode[code]
+	if !ok {
+		return DirectoryStation{}, false
+	}
+	return d.stations[idx], true
+}
+
+// BySystemCode ищет станцию по коду в системе кодирования, например EsrSystem и «191602»
+func (d *StationDirectory) BySystemCode(s system, code string) (DirectoryStation, bool) {
+	if s == YandexSystem {
+		return d.ByCode(code)
+	}
+	idx, ok := d.bySystem[s.String()+"_code"][code]
+	if !ok {
+		return DirectoryStation{}, false
+	}
+	return d.stations[idx], true
+}
+
+// ByTitle ищет станции по названию без учета регистра
+func (d *StationDirectory) ByTitle(title string) []DirectoryStation {
+	return d.collect(d.byTitle[normalizeTitle(title)])
+}
+
+// BySettlement возвращает станции населенного пункта по его коду, например «c213»
+func (d *StationDirectory) BySettlement(code string) []DirectoryStation {
+	return d.collect(d.bySettlement[code])
+}
+
+// BySettlementTitle возвращает станции населенных пунктов с указанным названием без учета регистра
+func (d *StationDirectory) BySettlementTitle(title string) []DirectoryStation {
+	return d.collect(d.bySettlementTitle[normalizeTitle(title)])
+}
+
+// Save записывает справочник в формате ответа stations_list для LoadStationDirectory.
+// Название и коды каждого уровня пишутся до вложенного списка, поэтому снимок
+// читается потоком без накопления станций.
+func (d *StationDirectory) Save(w io.Writer) error {
+	var snap stationsSnapshot
+	var country *countrySnapshot
+	var region *regionSnapshot
+	var settlement *settlementSnapshot
+	for _, s := range d.stations {
+		if country == nil || country.Name != s.Country.Name {
+			snap.Countries = append(snap.Countries, countrySnapshot{Name: s.Country.Name, Codes: s.Country.Codes})
+			country = &snap.Countries[len(snap.Countries)-1]
+			region = nil
+		}
+		if region == nil || region.Name != s.Region.Name {
+			country.Regions = append(country.Regions, regionSnapshot{Name: s.Region.Name, Codes: s.Region.Codes})
+			region = &country.Regions[len(country.Regions)-1]
+			settlement = nil
+		}
+		if settlement == nil || settlement.Name != s.Settlement.Name {
+			region.Settlements = append(region.Settlements, settlementSnapshot{Name: s.Settlement.Name, Codes: s.Settlement.Codes})
+			settlement = &region.Settlements[len(region.Settlements)-1]
+		}
+		station := s.Station
+		station.Region = ""
+		station.City = ""
+		settlement.Stations = append(settlement.Stations, station)
+	}
+	return json.NewEncoder(w).Encode(snap)
+}
+
+// stationsSnapshot и вложенные типы задают порядок ключей снимка Save независимо от StationsListResponse
+type stationsSnapshot struct {
+	Countries []countrySnapshot `json:"countries"`
+}
+
+type countrySnapshot struct {
+	Name    string           `json:"title"`
+	Codes   Codes            `json:"codes"`
+	Regions []regionSnapshot `json:"regions"`
+}
+
+type regionSnapshot struct {
+	Name        string               `json:"title"`
+	Codes       Codes                `json:"codes"`
+	Settlements []settlementSnapshot `json:"settlements"`
+}
+
+type settlementSnapshot struct {
+	Name     string    `json:"title"`
+	Codes    Codes     `json:"codes"`
+	Stations []Station `json:"stations"`
+}
+
+func (d *StationDirectory) collect(idxs []int) []DirectoryStation {
+	if len(idxs) == 0 {
+		return nil
+	}
+	stations := make([]DirectoryStation, 0, len(idxs))
+	for _, idx := range idxs {
+		stations = append(stations, d.stations[idx])
+	}
+	return stations
+}
+
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
@@ -0,0 +1,78 @@
+package yandex
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStationDirectory(t *testing.T) {
+	f, err := os.Open("testdata/stations_list.json")
+	require.NoError(t, err)
+	defer f.Close()
+
+	d, err := LoadStationDirectory(f)
+	require.NoError(t, err)
+	require.Equal(t, 2, d.Len())
+
+	s, ok := d.ByCode("s2000001")
+	require.True(t, ok)
+	assert.Equal(t, "Курский вокзал", s.Title)
+	assert.Equal(t, "Москва", s.Settlement.Name)
+	assert.Equal(t, "Москва", s.City)
+	assert.Equal(t, "Москва и Московская область", s.Region.Name)
+	assert.Equal(t, "Москва и Московская область", s.Station.Region)
+	assert.Equal(t, "Россия", s.Country.Name)
+	assert.Nil(t, s.Settlement.Stations)
+
+	s, ok = d.BySystemCode(EsrSystem, "191602")
+	require.True(t, ok)
+	assert.Equal(t, "s2000001", s.Code)
+
+	s, ok = d.BySystemCode(YandexSystem, "s9876336")
+	require.True(t, ok)
+	assert.Equal(t, "Улица Лобачевского", s.Title)
+
+	_, ok = d.BySystemCode(ExpressSystem, "191602")
+	assert.False(t, ok)
+
+	assert.Len(t, d.ByTitle("  курский ВОКЗАЛ"), 1)
+	assert.Len(t, d.BySettlement("c213"), 2)
+	assert.Len(t, d.BySettlementTitle("москва"), 2)
+	assert.Empty(t, d.BySettlement("c2"))
+}
+
+func TestStationDirectory_Save(t *testing.T) {
+	resp, err := newFixtureClient(t, XmlFormat).StationsList(context.TODO())
+	require.NoError(t, err)
+	d := NewStationDirectory(resp)
+
+	var buf bytes.Buffer
+	require.NoError(t, d.Save(&buf))
+
+	// Заголовок каждого уровня записан до его вложенного списка
+	out := buf.String()
+	for _, keys := range [][2]string{
+		{`"countries":[{"title"`, `"regions"`},
+		{`"regions":[{"title"`, `"settlements"`},
+		{`"settlements":[{"title"`, `"stations"`},
+	} {
+		require.Contains(t, out, keys[0])
+		assert.Less(t, strings.Index(out, keys[0]), strings.Index(out, keys[1]))
+	}
+
+	w := newStationsWalker(bytes.NewReader(buf.Bytes()), StationsFilter{}, func(Country, Region, Settlement, Station) error {
+		return nil
+	})
+	require.NoError(t, w.walk())
+	assert.Equal(t, 0, w.peak)
+
+	loaded, err := LoadStationDirectory(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, d.Stations(), loaded.Stations())
+}
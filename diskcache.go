@@ -0,0 +1,70 @@
+package yandex
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DiskCache кеш в файлах каталога. Каждый ответ хранится в отдельном файле,
+// имя которого — хеш ключа, поэтому каталог можно разделять между процессами.
+type DiskCache struct {
+	dir string
+	now func() time.Time
+}
+
+// NewDiskCache возвращает кеш в каталоге dir, создавая его при необходимости
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{
+		dir: dir,
+		now: time.Now,
+	}, nil
+}
+
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil || len(data) < 8 {
+		return nil, false
+	}
+	expires := time.Unix(0, int64(binary.BigEndian.Uint64(data)))
+	if !c.now().Before(expires) {
+		os.Remove(c.path(key))
+		return nil, false
+	}
+	return data[8:], true
+}
+
+// Set записывает ответ во временный файл и переименовывает его, чтобы читатели не видели неполную запись
+func (c *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	data := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(c.now().Add(ttl).UnixNano()))
+	copy(data[8:], value)
+
+	f, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	if err := os.Rename(f.Name(), c.path(key)); err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,115 @@
+package yandex
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrValidation    = errors.New("invalid request")        // Некорректные параметры запроса
+	ErrQuotaExceeded = errors.New("quota exceeded")         // Исчерпана квота ключа (ответ 429) или всех ключей пула
+	ErrInvalidKey    = errors.New("invalid api key")        // Ключ не принят API (ответы 401, 403)
+	ErrNotFound      = errors.New("not found")              // Объект не найден (ответ 404)
+	ErrNoKeys        = errors.New("pool api keys is empty") // В пуле нет доступных ключей
+)
+
+// errKeysExhausted все ключи пула исчерпали суточную квоту или простаивают после ответа 429.
+// Соответствует ErrQuotaExceeded и ErrNoKeys.
+var errKeysExhausted error = keysExhaustedError{}
+
+type keysExhaustedError struct{}
+
+func (keysExhaustedError) Error() string {
+	return ErrQuotaExceeded.Error() + ": " + ErrNoKeys.Error()
+}
+
+func (keysExhaustedError) Is(target error) bool {
+	return target == ErrQuotaExceeded || target == ErrNoKeys
+}
+
+// APIError ошибка, которую вернуло API Яндекс Расписаний
+type APIError struct {
+	StatusCode int    // HTTP код ответа
+	Endpoint   string // Метод API, например «search»
+	Code       string // Код ошибки Яндекс Расписаний
+	Text       string // Описание ошибки
+	Request    string // Запрос, вызвавший ошибку, с замаскированным API ключом
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %d status code: %s", e.Endpoint, e.StatusCode, e.Text)
+}
+
+// Is сопоставляет код ответа с ErrQuotaExceeded, ErrInvalidKey, ErrNotFound и ErrValidation
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrQuotaExceeded:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInvalidKey:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest
+	}
+	return false
+}
+
+type errorPayload struct {
+	Error struct {
+		Text     string `json:"text" xml:"text"`
+		HTTPCode int    `json:"http_code" xml:"http_code"`
+		Code     string `json:"error_code" xml:"error_code"`
+		Request  string `json:"request" xml:"request"`
+	} `json:"error" xml:"error"`
+}
+
+// newAPIError разбирает тело ответа с ошибкой в формате f
+func newAPIError(endpoint string, status int, f format, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: status,
+		Endpoint:   endpoint,
+	}
+
+	var payload errorPayload
+	var err error
+	switch f {
+	case XmlFormat:
+		err = xml.Unmarshal(body, &payload)
+	default:
+		err = json.Unmarshal(body, &payload)
+	}
+	if err != nil || payload.Error.Text == "" {
+		e.Text = strings.TrimSpace(string(body))
+		return e
+	}
+
+	e.Code = payload.Error.Code
+	e.Text = payload.Error.Text
+	e.Request = maskURL(payload.Error.Request)
+	return e
+}
+
+// validationError возвращает ошибку проверки параметров запроса, совместимую с ErrValidation
+func validationError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrValidation}, args...)...)
+}
+
+// maskURL заменяет маской API ключ в ссылке на запрос
+func maskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	if key := q.Get("apikey"); key != "" {
+		q.Set("apikey", maskKey(key))
+		u.RawQuery = strings.Replace(q.Encode(), "%2A", "*", -1)
+	}
+	return u.String()
+}
@@ -0,0 +1,91 @@
+package yandex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_APIError(t *testing.T) {
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		resp := okResponse(`{"error": {
+			"text": "Не нашли объект по yandex коду s1",
+			"http_code": 404,
+			"error_code": "not_found",
+			"request": "https://api.rasp.yandex.net/v3.0/thread/?apikey=test-key&uid=s1"
+		}}`)
+		resp.StatusCode = http.StatusNotFound
+		return resp, nil
+	})
+
+	_, err := c.Thread(context.TODO(), ThreadRequest{UID: "s1"})
+	require.Error(t, err)
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "thread", apiErr.Endpoint)
+	assert.Equal(t, "not_found", apiErr.Code)
+	assert.Equal(t, "Не нашли объект по yandex коду s1", apiErr.Text)
+	assert.Equal(t, "https://api.rasp.yandex.net/v3.0/thread/?apikey=****&uid=s1", apiErr.Request)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrQuotaExceeded))
+}
+
+func TestClient_APIErrorXML(t *testing.T) {
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		resp := okResponse(`<response><error><text>Неверный ключ</text><http_code>403</http_code><error_code>invalid_key</error_code></error></response>`)
+		resp.StatusCode = http.StatusForbidden
+		return resp, nil
+	})
+	c.cfg.Format = XmlFormat
+
+	_, err := c.Copyright(context.TODO())
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "invalid_key", apiErr.Code)
+	assert.Equal(t, "Неверный ключ", apiErr.Text)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestAPIError_PlainBody(t *testing.T) {
+	err := newAPIError("search", http.StatusTooManyRequests, JsonFormat, []byte("Too Many Requests\n"))
+
+	assert.Equal(t, "Too Many Requests", err.Text)
+	assert.Equal(t, "search: 429 status code: Too Many Requests", err.Error())
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+}
+
+func TestClient_ValidationError(t *testing.T) {
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("unexpected request")
+		return nil, nil
+	})
+
+	_, err := c.Thread(context.TODO(), ThreadRequest{})
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	_, err = c.Search(context.TODO(), SearchRequest{From: "c213", To: "c2", TransportType: "car"})
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	_, err = c.Carrier(context.TODO(), CarrierRequest{Code: "SU", System: AllSystems})
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	_, err = c.Carrier(context.TODO(), CarrierRequest{Code: "SU", System: "unknown"})
+	assert.True(t, errors.Is(err, ErrValidation))
+}
+
+func TestClient_NoKeys(t *testing.T) {
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		return okResponse(`{}`), nil
+	})
+	c.pool = NewKeyPool(nil, KeyPoolConfig{})
+
+	_, err := c.Copyright(context.TODO())
+	assert.True(t, errors.Is(err, ErrNoKeys))
+}
@@ -0,0 +1,17 @@
+package yandex
+
+// event событие, по которому фильтруется расписание станции
+type event string
+
+func (e event) String() string {
+	return string(e)
+}
+
+const (
+	DepartureEvent event = "departure" // Отправление
+	ArrivalEvent   event = "arrival"   // Прибытие
+)
+
+func (e event) valid() bool {
+	return e == DepartureEvent || e == ArrivalEvent
+}
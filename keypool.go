@@ -0,0 +1,213 @@
+package yandex
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Квоты Яндекс Расписаний обнуляются в полночь по московскому времени
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+// KeyStrategy способ выбора ключа из пула
+type KeyStrategy int
+
+const (
+	RoundRobin KeyStrategy = iota // Ключи выбираются по очереди
+	LeastUsed                     // Выбирается ключ с наименьшим числом запросов за сутки
+)
+
+// DefaultKeyCooldown время простоя ключа после ответа 429 по умолчанию
+const DefaultKeyCooldown = time.Minute
+
+type KeyPoolConfig struct {
+	Strategy   KeyStrategy
+	DailyLimit int           // Суточная квота одного ключа, 0 — без ограничений
+	Cooldown   time.Duration // Время простоя ключа после ответа 429, 0 — DefaultKeyCooldown
+
+	// QuotaCodes коды ошибок API, означающие исчерпание суточной квоты ключа.
+	// Получив такой ответ, ключ простаивает до начала следующих суток по Москве.
+	QuotaCodes []string
+}
+
+// KeyPool пул API ключей, безопасен для конкурентного использования
+type KeyPool struct {
+	mu   sync.Mutex
+	cfg  KeyPoolConfig
+	keys []*poolKey
+	next int
+	now  func() time.Time
+}
+
+type poolKey struct {
+	key           string
+	day           string // Московские сутки, к которым относится used
+	used          int
+	cooldownUntil time.Time
+}
+
+// KeyStatus состояние ключа в пуле
+type KeyStatus struct {
+	Key           string    // Маскированный ключ
+	Used          int       // Запросов за текущие сутки
+	Remaining     int       // Остаток суточной квоты, -1 — без ограничений
+	CooldownUntil time.Time // Время окончания простоя после ответа 429
+	Available     bool      // Ключ может быть выдан сейчас
+}
+
+func NewKeyPool(keys []string, cfg KeyPoolConfig) *KeyPool {
+	p := &KeyPool{
+		cfg: cfg,
+		now: time.Now,
+	}
+	for _, k := range keys {
+		p.keys = append(p.keys, &poolKey{key: k})
+	}
+	return p
+}
+
+// Status возвращает состояние всех ключей пула
+func (p *KeyPool) Status() []KeyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	statuses := make([]KeyStatus, 0, len(p.keys))
+	for _, k := range p.keys {
+		p.reset(k, now)
+		remaining := -1
+		if p.cfg.DailyLimit > 0 {
+			remaining = p.cfg.DailyLimit - k.used
+		}
+		statuses = append(statuses, KeyStatus{
+			Key:           maskKey(k.key),
+			Used:          k.used,
+			Remaining:     remaining,
+			CooldownUntil: k.cooldownUntil,
+			Available:     p.available(k, now),
+		})
+	}
+	return statuses
+}
+
+// acquire выдает доступный ключ и его индекс в пуле, учитывая запрос в суточной квоте
+func (p *KeyPool) acquire() (string, int, error) {
+	if p == nil {
+		return "", 0, ErrNoKeys
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	idx := -1
+	for i := range p.keys {
+		j := (p.next + i) % len(p.keys)
+		k := p.keys[j]
+		p.reset(k, now)
+		if !p.available(k, now) {
+			continue
+		}
+		if p.cfg.Strategy == RoundRobin {
+			idx = j
+			break
+		}
+		if idx == -1 || k.used < p.keys[idx].used {
+			idx = j
+		}
+	}
+	if idx == -1 {
+		if len(p.keys) == 0 {
+			return "", 0, ErrNoKeys
+		}
+		return "", 0, errKeysExhausted
+	}
+
+	p.keys[idx].used++
+	p.next = idx + 1
+	return p.keys[idx].key, idx, nil
+}
+
+// cooldown выводит ключ из ротации после ответа 429 на cfg.Cooldown
+func (p *KeyPool) cooldown(idx int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d := p.cfg.Cooldown
+	if d <= 0 {
+		d = DefaultKeyCooldown
+	}
+	p.keys[idx].cooldownUntil = p.now().Add(d)
+}
+
+// exhaust выводит ключ из ротации до начала следующих суток по Москве
+func (p *KeyPool) exhaust(idx int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.keys[idx].cooldownUntil = nextMoscowDay(p.now())
+}
+
+// limited выводит ключ из ротации после ответа 429: до следующих суток, если ответ
+// сообщает об исчерпании квоты кодом из cfg.QuotaCodes, иначе на cfg.Cooldown
+func (p *KeyPool) limited(idx int, err error) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		for _, code := range p.cfg.QuotaCodes {
+			if code == apiErr.Code {
+				p.exhaust(idx)
+				return
+			}
+		}
+	}
+	p.cooldown(idx)
+}
+
+// reset обнуляет счетчик ключа при смене московских суток
+func (p *KeyPool) reset(k *poolKey, now time.Time) {
+	day := now.In(moscow).Format(dateFormat)
+	if k.day != day {
+		k.day = day
+		k.used = 0
+	}
+}
+
+func (p *KeyPool) available(k *poolKey, now time.Time) bool {
+	if now.Before(k.cooldownUntil) {
+		return false
+	}
+	return p.cfg.DailyLimit <= 0 || k.used < p.cfg.DailyLimit
+}
+
+func nextMoscowDay(t time.Time) time.Time {
+	y, m, d := t.In(moscow).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, moscow)
+}
+
+// maskKey скрывает ключ, оставляя первые символы для различения ключей
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "****"
+}
+
+// String описывает пул без раскрытия ключей
+func (p *KeyPool) String() string {
+	if p == nil {
+		return "KeyPool(nil)"
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	masked := make([]string, 0, len(p.keys))
+	for _, k := range p.keys {
+		masked = append(masked, maskKey(k.key))
+	}
+	return "KeyPool" + fmt.Sprint(masked)
+}
+
+func (p *KeyPool) GoString() string {
+	return p.String()
+}
@@ -0,0 +1,151 @@
+package yandex
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPool_RoundRobin(t *testing.T) {
+	p := NewKeyPool([]string{"a", "b", "c"}, KeyPoolConfig{})
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		key, _, err := p.acquire()
+		require.NoError(t, err)
+		got = append(got, key)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, got)
+}
+
+func TestKeyPool_LeastUsed(t *testing.T) {
+	p := NewKeyPool([]string{"a", "b"}, KeyPoolConfig{Strategy: LeastUsed})
+
+	key, _, err := p.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, "a", key)
+
+	key, _, err = p.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, "b", key)
+
+	p.keys[0].used = 5
+	p.next = 0
+	key, _, err = p.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, "b", key)
+}
+
+func TestKeyPool_DailyLimit(t *testing.T) {
+	now := time.Date(2020, 1, 10, 20, 0, 0, 0, time.UTC) // 23:00 MSK
+	p := NewKeyPool([]string{"a"}, KeyPoolConfig{DailyLimit: 2})
+	p.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, _, err := p.acquire()
+		require.NoError(t, err)
+	}
+	_, _, err := p.acquire()
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.True(t, errors.Is(err, ErrNoKeys))
+	assert.Equal(t, 0, p.Status()[0].Remaining)
+
+	now = now.Add(time.Hour) // 00:00 MSK
+	_, _, err = p.acquire()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p.Status()[0].Remaining)
+}
+
+func TestKeyPool_Cooldown(t *testing.T) {
+	now := time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)
+	p := NewKeyPool([]string{"a", "b"}, KeyPoolConfig{Cooldown: time.Minute})
+	p.now = func() time.Time { return now }
+
+	_, idx, err := p.acquire()
+	require.NoError(t, err)
+	p.cooldown(idx)
+
+	for i := 0; i < 2; i++ {
+		key, _, err := p.acquire()
+		require.NoError(t, err)
+		assert.Equal(t, "b", key)
+	}
+
+	now = now.Add(time.Minute)
+	key, _, err := p.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, "a", key)
+}
+
+func TestKeyPool_DefaultCooldown(t *testing.T) {
+	now := time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)
+	p := NewKeyPool([]string{"a"}, KeyPoolConfig{})
+	p.now = func() time.Time { return now }
+
+	_, idx, err := p.acquire()
+	require.NoError(t, err)
+	p.limited(idx, &APIError{StatusCode: http.StatusTooManyRequests})
+
+	status := p.Status()[0]
+	assert.False(t, status.Available)
+	assert.True(t, status.CooldownUntil.Equal(now.Add(DefaultKeyCooldown)))
+
+	now = now.Add(DefaultKeyCooldown)
+	_, _, err = p.acquire()
+	assert.NoError(t, err)
+}
+
+func TestKeyPool_CooldownUntilMoscowDay(t *testing.T) {
+	now := time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)
+	p := NewKeyPool([]string{"a"}, KeyPoolConfig{QuotaCodes: []string{"daily_limit"}})
+	p.now = func() time.Time { return now }
+
+	_, idx, err := p.acquire()
+	require.NoError(t, err)
+	p.limited(idx, &APIError{StatusCode: http.StatusTooManyRequests, Code: "daily_limit"})
+
+	status := p.Status()[0]
+	assert.False(t, status.Available)
+	assert.True(t, status.CooldownUntil.Equal(time.Date(2020, 1, 10, 21, 0, 0, 0, time.UTC)))
+}
+
+func TestKeyPool_Status(t *testing.T) {
+	p := NewKeyPool([]string{"0123456789abcdef"}, KeyPoolConfig{})
+
+	status := p.Status()
+	require.Len(t, status, 1)
+	assert.Equal(t, "0123****", status[0].Key)
+	assert.Equal(t, -1, status[0].Remaining)
+	assert.True(t, status[0].Available)
+}
+
+func TestKeyPool_Concurrent(t *testing.T) {
+	p := NewKeyPool([]string{"a", "b", "c"}, KeyPoolConfig{DailyLimit: 100})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 30; j++ {
+				_, idx, err := p.acquire()
+				if err == nil && j%10 == 0 {
+					p.cooldown(idx)
+				}
+				p.Status()
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, s := range p.Status() {
+		total += s.Used
+	}
+	assert.True(t, total <= 300)
+}
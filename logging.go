@@ -0,0 +1,125 @@
+package yandex
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Level уровень события. Значения совпадают с уровнями log/slog.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+}
+
+// Logger получатель структурированных событий клиента, например адаптер NewSlogLogger.
+// API ключи в атрибутах заменены маской, ошибки переданы текстом.
+type Logger interface {
+	Log(ctx context.Context, level Level, msg string, attrs ...Attribute)
+}
+
+// LogEvent событие клиента
+type LogEvent string
+
+const (
+	EventKeyRotation LogEvent = "key_rotation" // Ответ 429, запрос повторяется со следующим ключом
+	EventRetry       LogEvent = "retry"        // Повтор запроса после ошибки сети или ответа 5xx
+	EventCacheHit    LogEvent = "cache_hit"    // Ответ взят из кеша
+	EventCacheMiss   LogEvent = "cache_miss"   // Ответа нет в кеше
+	EventSlowRequest LogEvent = "slow_request" // Вызов дольше LogConfig.SlowRequest
+	EventDecodeError LogEvent = "decode_error" // Ответ не удалось разобрать
+)
+
+// DefaultLogLevels уровни событий по умолчанию
+var DefaultLogLevels = map[LogEvent]Level{
+	EventKeyRotation: LevelWarn,
+	EventRetry:       LevelWarn,
+	EventCacheHit:    LevelDebug,
+	EventCacheMiss:   LevelDebug,
+	EventSlowRequest: LevelWarn,
+	EventDecodeError: LevelError,
+}
+
+// DefaultSlowRequest порог медленного вызова по умолчанию
+const DefaultSlowRequest = 2 * time.Second
+
+// LogConfig настройки журнала событий
+type LogConfig struct {
+	Level       Level              // Минимальный уровень передаваемых событий, по умолчанию LevelInfo
+	Levels      map[LogEvent]Level // Уровни событий, дополняют и переопределяют DefaultLogLevels
+	SlowRequest time.Duration      // Порог медленного вызова, 0 — DefaultSlowRequest, меньше 0 — не сообщать
+}
+
+// WithLogger передает события клиента в l
+func WithLogger(l Logger, cfg LogConfig) Option {
+	return func(o *options) error {
+		o.logger = &logger{Logger: l, cfg: cfg}
+		return nil
+	}
+}
+
+// logger передает события с уровнем не ниже cfg.Level, убирая ключи пула из атрибутов
+type logger struct {
+	Logger
+	cfg  LogConfig
+	pool *KeyPool
+}
+
+func (l *logger) level(event LogEvent) Level {
+	if level, ok := l.cfg.Levels[event]; ok {
+		return level
+	}
+	return DefaultLogLevels[event]
+}
+
+func (l *logger) slowRequest() time.Duration {
+	if l == nil || l.cfg.SlowRequest < 0 {
+		return 0
+	}
+	if l.cfg.SlowRequest == 0 {
+		return DefaultSlowRequest
+	}
+	return l.cfg.SlowRequest
+}
+
+// log передает событие event. Безопасен для nil.
+func (l *logger) log(ctx context.Context, event LogEvent, msg string, attrs ...Attribute) {
+	if l == nil {
+		return
+	}
+	level := l.level(event)
+	if level < l.cfg.Level {
+		return
+	}
+
+	redacted := make([]Attribute, 0, len(attrs)+1)
+	redacted = append(redacted, Attribute{Key: "event", Value: string(event)})
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			a.Value = l.pool.redact(v)
+		case error:
+			a.Value = l.pool.redact(v.Error())
+		}
+		redacted = append(redacted, a)
+	}
+	l.Log(ctx, level, msg, redacted...)
+}
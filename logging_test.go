@@ -0,0 +1,131 @@
+package yandex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLogger запоминает события журнала
+type memoryLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+type logEntry struct {
+	level Level
+	msg   string
+	attrs map[string]interface{}
+}
+
+func (l *memoryLogger) Log(ctx context.Context, level Level, msg string, attrs ...Attribute) {
+	e := logEntry{level: level, msg: msg, attrs: make(map[string]interface{})}
+	for _, a := range attrs {
+		e.attrs[a.Key] = a.Value
+	}
+	l.mu.Lock()
+	l.entries = append(l.entries, e)
+	l.mu.Unlock()
+}
+
+func (l *memoryLogger) events() []string {
+	var events []string
+	for _, e := range l.entries {
+		events = append(events, fmt.Sprintf("%s %v", e.level, e.attrs["event"]))
+	}
+	return events
+}
+
+func TestLogger_Events(t *testing.T) {
+	var calls int
+	log := &memoryLogger{}
+	c, err := New(
+		WithKeys(secretKey, "second-key-0000"),
+		WithRetry(RetryPolicy{BaseDelay: 1}),
+		WithCache(NewLRUCache(10), nil),
+		WithLogger(log, LogConfig{Level: LevelDebug}),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			switch calls {
+			case 1:
+				return statusResponse(http.StatusTooManyRequests), nil
+			case 2:
+				return statusResponse(http.StatusBadGateway), nil
+			}
+			return okResponse(`{"copyright": {"text": "ok"}}`), nil
+		})),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = c.Copyright(context.TODO())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{
+		"DEBUG cache_miss",
+		"WARN key_rotation",
+		"WARN retry",
+		"DEBUG cache_hit",
+	}, log.events())
+
+	rotation := log.entries[1].attrs
+	assert.Equal(t, "copyright", rotation["endpoint"])
+	assert.Equal(t, 0, rotation["key_index"])
+	assert.Equal(t, maskKey(secretKey), rotation["key"])
+
+	retry := log.entries[2].attrs
+	assert.Equal(t, http.StatusBadGateway, retry["status"])
+	assert.IsType(t, "", retry["error"])
+	for _, e := range log.entries {
+		for _, v := range e.attrs {
+			assert.NotContains(t, fmt.Sprint(v), secretKey)
+		}
+	}
+}
+
+func TestLogger_Levels(t *testing.T) {
+	log := &memoryLogger{}
+	c, err := New(
+		WithKeys("key"),
+		WithCache(NewLRUCache(10), nil),
+		WithLogger(log, LogConfig{Levels: map[LogEvent]Level{EventCacheMiss: LevelInfo}}),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return okResponse(`{"copyright": "not an object"}`), nil
+		})),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Copyright(context.TODO())
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"INFO cache_miss", "ERROR decode_error"}, log.events())
+	assert.Equal(t, "json", log.entries[1].attrs["format"])
+}
+
+func TestLogger_SlowRequest(t *testing.T) {
+	log := &memoryLogger{}
+	c, err := New(
+		WithKeys("key"),
+		WithLogger(log, LogConfig{SlowRequest: time.Millisecond}),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			time.Sleep(2 * time.Millisecond)
+			return okResponse(`{}`), nil
+		})),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Thread(context.TODO(), ThreadRequest{UID: "uid"})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"WARN slow_request"}, log.events())
+	assert.Equal(t, "thread", log.entries[0].attrs["endpoint"])
+	assert.Equal(t, 1, log.entries[0].attrs["attempts"])
+	assert.True(t, log.entries[0].attrs["latency"].(time.Duration) >= 2*time.Millisecond)
+}
@@ -0,0 +1,59 @@
+package yandex
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Metrics получатель метрик клиента. Реализации должны быть безопасны для конкурентного использования.
+// Ключи передаются маскированными вместе с номером в пуле. Номер однозначен только в пределах
+// одного пула: после замены пула клиента под тем же номером может оказаться другой ключ.
+type Metrics interface {
+	ObserveRequest(endpoint string, status int, latency time.Duration) // HTTP запрос, status 0 — ответ не получен
+	ObserveCacheHit(endpoint string)                                   // Ответ взят из кеша без HTTP запроса
+	ObserveRetry(endpoint string)                                      // Повтор запроса после ошибки или ответа 429
+	ObserveRateLimit(endpoint string, index int, key string)           // Ответ 429 для ключа
+	SetKeyQuota(index int, key string, used, remaining int)            // Суточное использование ключа, remaining -1 — без ограничений
+}
+
+// WithMetrics передает метрики запросов в m
+func WithMetrics(m Metrics) Option {
+	return func(o *options) error {
+		o.metrics = m
+		return nil
+	}
+}
+
+// metricsMiddleware сообщает метрики каждого HTTP запроса вызова, попадания в кеш и состояние ключей пула
+func metricsMiddleware(m Metrics, pool *KeyPool) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, call *Call) error {
+			err := next.Do(ctx, call)
+			if call.Cached {
+				m.ObserveCacheHit(call.Endpoint)
+			}
+
+			for i, a := range call.Attempts {
+				status := 0
+				if a.Response != nil {
+					status = a.Response.StatusCode
+				}
+				m.ObserveRequest(call.Endpoint, status, a.Latency)
+				if status == http.StatusTooManyRequests {
+					m.ObserveRateLimit(call.Endpoint, a.KeyIndex, maskKey(pool.keys[a.KeyIndex].key))
+				}
+				if i > 0 {
+					m.ObserveRetry(call.Endpoint)
+				}
+			}
+			if len(call.Attempts) > 0 {
+				for i, s := range pool.Status() {
+					m.SetKeyQuota(i, s.Key, s.Used, s.Remaining)
+				}
+			}
+
+			return err
+		})
+	}
+}
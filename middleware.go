@@ -0,0 +1,85 @@
+package yandex
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Call один вызов метода API. Middleware видит его до и после выполнения запроса.
+type Call struct {
+	Endpoint string      // Название метода API, например «search»
+	Request  interface{} // Типизированный запрос, например SearchRequest; nil для методов без параметров
+	URL      url.URL     // URL запроса без API ключа
+
+	// Result указатель на ответ, например *SearchResponse. Заполняется после успешного вызова.
+	// Для StationsListStream равен nil: станции передаются в StationsFunc.
+	Result interface{}
+
+	Attempts []Attempt     // HTTP запросы вызова, включая повторы; пусто при ответе из кеша
+	Cached   bool          // Ответ взят из кеша WithCache
+	Latency  time.Duration // Время выполнения вызова
+
+	run DoerFunc
+}
+
+// Attempt один HTTP запрос вызова
+type Attempt struct {
+	Request  *http.Request  // Запрос; ключ в заголовке Authorization заменен маской
+	Response *http.Response // Копия ответа с Request, nil если ответ не получен. Тело ответа читает и закрывает клиент.
+	Err      error          // Ошибка запроса
+	KeyIndex int            // Номер ключа в пуле
+	Latency  time.Duration  // Время до получения заголовков ответа
+}
+
+// Doer выполняет вызов метода API
+type Doer interface {
+	Do(ctx context.Context, call *Call) error
+}
+
+// DoerFunc функция, реализующая Doer
+type DoerFunc func(ctx context.Context, call *Call) error
+
+func (f DoerFunc) Do(ctx context.Context, call *Call) error {
+	return f(ctx, call)
+}
+
+// Middleware оборачивает выполнение вызова: логирование, метрики, трассировка, аудит.
+// Middleware может не вызывать next, например чтобы отдать ответ из своего кеша, заполнив call.Result.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware добавляет middleware клиента. Первая middleware выполняется первой.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *options) error {
+		o.middleware = append(o.middleware, mw...)
+		return nil
+	}
+}
+
+// chain оборачивает doer в middleware так, чтобы mw[0] была внешней
+func chain(doer Doer, mw []Middleware) Doer {
+	for i := len(mw) - 1; i >= 0; i-- {
+		doer = mw[i](doer)
+	}
+	return doer
+}
+
+type callKey struct{}
+
+// callFrom возвращает вызов, выполняемый с контекстом ctx
+func callFrom(ctx context.Context) *Call {
+	call, _ := ctx.Value(callKey{}).(*Call)
+	return call
+}
+
+// maskRequest возвращает копию запроса с маской вместо ключа
+func maskRequest(req *http.Request, key string) *http.Request {
+	masked := *req
+	masked.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		masked.Header[k] = append([]string(nil), v...)
+	}
+	masked.Header.Set("Authorization", maskKey(key))
+	return &masked
+}
@@ -0,0 +1,143 @@
+package yandex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Call(t *testing.T) {
+	var calls int
+	var order []string
+	var seen *Call
+	record := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, call *Call) error {
+				order = append(order, name)
+				return next.Do(ctx, call)
+			})
+		}
+	}
+	c, err := New(
+		WithKeys(secretKey),
+		WithRetry(RetryPolicy{BaseDelay: 1}),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return statusResponse(http.StatusBadGateway), nil
+			}
+			return okResponse(`{"uid": "752A_0_2"}`), nil
+		})),
+		WithMiddleware(record("outer"), record("inner"), func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, call *Call) error {
+				seen = call
+				return next.Do(ctx, call)
+			})
+		}),
+	)
+	require.NoError(t, err)
+
+	req := ThreadRequest{UID: "752A_0_2"}
+	resp, err := c.Thread(context.TODO(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+
+	require.NotNil(t, seen)
+	assert.Equal(t, "thread", seen.Endpoint)
+	assert.Equal(t, req, seen.Request)
+	assert.Equal(t, resp, seen.Result)
+	assert.False(t, seen.Cached)
+	assert.True(t, seen.Latency > 0)
+
+	require.Len(t, seen.Attempts, 2)
+	assert.Equal(t, http.StatusBadGateway, seen.Attempts[0].Response.StatusCode)
+	assert.Error(t, seen.Attempts[0].Err)
+	assert.Equal(t, http.StatusOK, seen.Attempts[1].Response.StatusCode)
+	assert.NoError(t, seen.Attempts[1].Err)
+	for _, a := range seen.Attempts {
+		assert.Equal(t, maskKey(secretKey), a.Request.Header.Get("Authorization"))
+		assert.Equal(t, 0, a.KeyIndex)
+	}
+}
+
+func TestMiddleware_ShortCircuit(t *testing.T) {
+	c, err := New(
+		WithKeys("key"),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			t.Fatal("unexpected request")
+			return nil, nil
+		})),
+		WithMiddleware(func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, call *Call) error {
+				if resp, ok := call.Result.(*CopyrightResponse); ok {
+					resp.Copyright.Text = "from middleware"
+					return nil
+				}
+				return next.Do(ctx, call)
+			})
+		}),
+	)
+	require.NoError(t, err)
+
+	resp, err := c.Copyright(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "from middleware", resp.Copyright.Text)
+}
+
+func TestMiddleware_Stream(t *testing.T) {
+	var seen *Call
+	c, err := New(
+		WithKeys("key"),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return okResponse(`{"countries": []}`), nil
+		})),
+		WithMiddleware(func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, call *Call) error {
+				seen = call
+				return next.Do(ctx, call)
+			})
+		}),
+	)
+	require.NoError(t, err)
+
+	filter := StationsFilter{Countries: []string{"Россия"}}
+	require.NoError(t, c.StationsListStream(context.TODO(), filter, func(Country, Region, Settlement, Station) error {
+		return nil
+	}))
+	require.NotNil(t, seen)
+	assert.Equal(t, "stations_list", seen.Endpoint)
+	assert.Equal(t, filter, seen.Request)
+	assert.Nil(t, seen.Result)
+	assert.Len(t, seen.Attempts, 1)
+}
+
+func TestMiddleware_NoKeyLeak(t *testing.T) {
+	var seen *Call
+	c, err := New(
+		WithKeys(secretKey),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := okResponse(`{"copyright": {}}`)
+			resp.Request = req
+			return resp, nil
+		})),
+		WithMiddleware(func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, call *Call) error {
+				seen = call
+				return next.Do(ctx, call)
+			})
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Copyright(context.TODO())
+	require.NoError(t, err)
+
+	require.Len(t, seen.Attempts, 1)
+	a := seen.Attempts[0]
+	assert.Equal(t, maskKey(secretKey), a.Response.Request.Header.Get("Authorization"))
+	assert.NotContains(t, fmt.Sprintf("%+v %+v %+v", seen, a.Request, a.Response.Request), secretKey)
+}
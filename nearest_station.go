@@ -0,0 +1,66 @@
+package yandex
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// Станция из ответа nearest_stations
+type NearestStation struct {
+	Distance        float64       `json:"distance" xml:"distance"`
+	Code            string        `json:"code" xml:"code"`
+	StationType     string        `json:"station_type" xml:"station_type"`
+	TypeChoices     TypeChoices   `json:"type_choices" xml:"type_choices"`
+	Title           string        `json:"title" xml:"title"`
+	TransportType   TransportType `json:"transport_type" xml:"transport_type"`
+	Lat             float64       `json:"lat" xml:"lat"`
+	Lng             float64       `json:"lng" xml:"lng"`
+	Type            string        `json:"type" xml:"type"`
+	StationTypeName string        `json:"station_type_name" xml:"station_type_name"`
+	Majority        int           `json:"majority" xml:"majority"`
+}
+
+// TypeChoices ссылки на расписание станции на Яндекс Расписаниях по видам расписания, например schedule или suburban
+type TypeChoices map[string]TypeChoice
+
+type TypeChoice struct {
+	DesktopURL string `json:"desktop_url" xml:"desktop_url"`
+	TouchURL   string `json:"touch_url" xml:"touch_url"`
+}
+
+func (c *TypeChoices) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*c = make(TypeChoices)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var v TypeChoice
+			if err := d.DecodeElement(&v, &t); err != nil {
+				return err
+			}
+			(*c)[t.Name.Local] = v
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (c TypeChoices) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := e.EncodeElement(c[k], xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
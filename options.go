@@ -0,0 +1,259 @@
+package yandex
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const defaultUserAgent = "ya-rasp"
+
+// Option настройка клиента для New
+type Option func(*options) error
+
+type options struct {
+	cfg        config
+	keys       []string
+	httpClient *http.Client
+	proxy      *url.URL
+	middleware []Middleware
+	metrics    Metrics
+	tracer     Tracer
+	logger     *logger
+}
+
+// WithKeys задает API ключи. Ключи объединяются в пул с выбором по очереди и без суточной квоты.
+func WithKeys(keys ...string) Option {
+	return func(o *options) error {
+		o.keys = append(o.keys, keys...)
+		return nil
+	}
+}
+
+// WithKeyPool задает пул API ключей, например с суточной квотой или общий для нескольких клиентов
+func WithKeyPool(pool *KeyPool) Option {
+	return func(o *options) error {
+		o.cfg.KeyPool = pool
+		return nil
+	}
+}
+
+// WithHTTPClient задает HTTP клиент. Таймаут и транспорт берутся из него: WithTimeout не действует,
+// WithTransport и WithProxy с ним несовместимы.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) error {
+		if c == nil {
+			return errors.New("http client is nil")
+		}
+		o.httpClient = c
+		return nil
+	}
+}
+
+// WithTransport задает транспорт HTTP запросов, например yarasptest.Recorder
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) error {
+		if rt == nil {
+			return errors.New("transport is nil")
+		}
+		o.cfg.Transport = rt
+		return nil
+	}
+}
+
+// WithHost задает адрес API, по умолчанию api.rasp.yandex.net
+func WithHost(host string) Option {
+	return func(o *options) error {
+		o.cfg.Host = host
+		return nil
+	}
+}
+
+// WithScheme задает схему запросов: https (по умолчанию) или http
+func WithScheme(scheme string) Option {
+	return func(o *options) error {
+		o.cfg.Scheme = scheme
+		return nil
+	}
+}
+
+// WithUserAgent задает заголовок User-Agent запросов
+func WithUserAgent(ua string) Option {
+	return func(o *options) error {
+		o.cfg.UserAgent = ua
+		return nil
+	}
+}
+
+// WithLang задает язык ответов, по умолчанию Ru
+func WithLang(l lang) Option {
+	return func(o *options) error {
+		o.cfg.Lang = l
+		return nil
+	}
+}
+
+// WithFormat задает формат ответов, по умолчанию JsonFormat
+func WithFormat(f format) Option {
+	return func(o *options) error {
+		o.cfg.Format = f
+		return nil
+	}
+}
+
+// WithTimeout задает таймаут одного HTTP запроса, по умолчанию 15s
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		o.cfg.Timeout = d
+		return nil
+	}
+}
+
+// WithProxy задает прокси для HTTP запросов
+func WithProxy(proxy string) Option {
+	return func(o *options) error {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy: %v", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid proxy %q", proxy)
+		}
+		o.proxy = u
+		return nil
+	}
+}
+
+// WithRetry задает политику повтора запросов, по умолчанию DefaultRetryPolicy
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) error {
+		o.cfg.Retry = policy
+		return nil
+	}
+}
+
+// WithCache задает кеш ответов и время жизни ответов по методам API, дополняющее DefaultCacheTTL
+func WithCache(cache Cache, ttl map[string]time.Duration) Option {
+	return func(o *options) error {
+		o.cfg.Cache = cache
+		o.cfg.CacheTTL = ttl
+		return nil
+	}
+}
+
+// New возвращает клиент. Настройки проверяются при создании, ошибка возвращается вместо паники при первом запросе.
+func New(opts ...Option) (Client, error) {
+	o := options{
+		cfg: config{
+			Scheme:    defaultScheme,
+			Host:      defaultHost,
+			Format:    JsonFormat,
+			Lang:      Ru,
+			Version:   apiVersion,
+			Timeout:   15 * time.Second,
+			UserAgent: defaultUserAgent,
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := o.cfg
+	if len(o.keys) > 0 {
+		if cfg.KeyPool != nil {
+			return nil, errors.New("keys and key pool are both set")
+		}
+		cfg.KeyPool = NewKeyPool(o.keys, KeyPoolConfig{})
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	httpClient := o.httpClient
+	if httpClient != nil {
+		if cfg.Transport != nil || o.proxy != nil {
+			return nil, errors.New("http client is set together with transport or proxy")
+		}
+	} else {
+		httpClient = &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: cfg.Transport,
+		}
+		if httpClient.Transport == nil {
+			httpClient.Transport = newTransport(o.proxy)
+		} else if o.proxy != nil {
+			return nil, errors.New("proxy is set together with transport")
+		}
+	}
+
+	c := &client{
+		client: httpClient,
+		cfg:    &cfg,
+		pool:   cfg.KeyPool,
+		log:    o.logger,
+	}
+	if c.log != nil {
+		c.log.pool = cfg.KeyPool
+	}
+	middleware := o.middleware
+	if o.tracer != nil {
+		middleware = append([]Middleware{tracingMiddleware(o.tracer)}, middleware...)
+	}
+	if o.metrics != nil {
+		middleware = append(middleware, metricsMiddleware(o.metrics, cfg.KeyPool))
+	}
+	if len(middleware) > 0 {
+		c.doer = chain(DoerFunc(c.exec), middleware)
+	}
+	return c, nil
+}
+
+func (c *config) validate() error {
+	if c.KeyPool == nil || len(c.KeyPool.keys) == 0 {
+		return errors.New("api keys are missing")
+	}
+	for _, k := range c.KeyPool.keys {
+		if k.key == "" {
+			return errors.New("api key is empty")
+		}
+	}
+	if c.Scheme != "http" && c.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", c.Scheme)
+	}
+	if c.Host == "" {
+		return errors.New("host is missing")
+	}
+	if c.Format != JsonFormat && c.Format != XmlFormat {
+		return fmt.Errorf("unsupported format %q", c.Format)
+	}
+	if c.Lang != Ru && c.Lang != Ua {
+		return fmt.Errorf("unsupported lang %q", c.Lang)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("negative timeout %s", c.Timeout)
+	}
+	return nil
+}
+
+func newTransport(proxy *url.URL) *http.Transport {
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if proxy != nil {
+		t.Proxy = http.ProxyURL(proxy)
+	}
+	return t
+}
@@ -0,0 +1,72 @@
+package yandex
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Defaults(t *testing.T) {
+	c, err := New(WithKeys("key"))
+	require.NoError(t, err)
+
+	cfg := c.(*client).cfg
+	assert.Equal(t, "https", cfg.Scheme)
+	assert.Equal(t, defaultHost, cfg.Host)
+	assert.Equal(t, JsonFormat, cfg.Format)
+	assert.Equal(t, Ru, cfg.Lang)
+	assert.Equal(t, apiVersion, cfg.Version)
+	assert.Equal(t, 15*time.Second, c.(*client).client.Timeout)
+}
+
+func TestNew_Options(t *testing.T) {
+	var header http.Header
+	c, err := New(
+		WithKeys("key"),
+		WithScheme("http"),
+		WithHost("localhost:8080"),
+		WithUserAgent("test-agent"),
+		WithLang(Ua),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			header = req.Header
+			assert.Equal(t, "http", req.URL.Scheme)
+			assert.Equal(t, "localhost:8080", req.URL.Host)
+			assert.Equal(t, "uk_UA", req.URL.Query().Get("lang"))
+			return okResponse(`{}`), nil
+		})),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Thread(context.TODO(), ThreadRequest{UID: "uid"})
+	require.NoError(t, err)
+	assert.Equal(t, "test-agent", header.Get("User-Agent"))
+	assert.Equal(t, "key", header.Get("Authorization"))
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := map[string][]Option{
+		"no keys":               nil,
+		"empty key":             {WithKeys("")},
+		"keys and pool":         {WithKeys("key"), WithKeyPool(NewKeyPool([]string{"key"}, KeyPoolConfig{}))},
+		"scheme":                {WithKeys("key"), WithScheme("ftp")},
+		"host":                  {WithKeys("key"), WithHost("")},
+		"format":                {WithKeys("key"), WithFormat(format("yaml"))},
+		"lang":                  {WithKeys("key"), WithLang(lang("en_US"))},
+		"timeout":               {WithKeys("key"), WithTimeout(-time.Second)},
+		"proxy":                 {WithKeys("key"), WithProxy("localhost")},
+		"nil http client":       {WithKeys("key"), WithHTTPClient(nil)},
+		"http client and proxy": {WithKeys("key"), WithHTTPClient(http.DefaultClient), WithProxy("http://localhost:3128")},
+		"transport and proxy":   {WithKeys("key"), WithTransport(http.DefaultTransport), WithProxy("http://localhost:3128")},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(opts...)
+			assert.Error(t, err)
+			assert.Nil(t, c)
+		})
+	}
+}
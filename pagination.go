@@ -1,7 +1,149 @@
 package yandex
 
+import (
+	"context"
+	"errors"
+)
+
 type Pagination struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
-	Total  int `json:"total"`
+	Limit  int `json:"limit" xml:"limit"`
+	Offset int `json:"offset" xml:"offset"`
+	Total  int `json:"total" xml:"total"`
+}
+
+// ErrStop возвращается из функции обхода, чтобы остановить обход без ошибки
+var ErrStop = errors.New("stop iteration")
+
+// SearchIter обходит все рейсы поиска, начиная с req.Offset и запрашивая страницы по req.Limit.
+// Страницы запрашиваются по мере обхода. Интервальные рейсы не учитываются API в pagination.total
+// и в смещении, поэтому передаются в fn один раз, после обычных рейсов первой страницы.
+func SearchIter(ctx context.Context, c Client, req SearchRequest, fn func(Segment) error) error {
+	first := true
+	return paginate(ctx, req.Offset, func(offset int) (int, Pagination, error) {
+		req.Offset = offset
+		resp, err := c.Search(ctx, req)
+		if err != nil {
+			return 0, Pagination{}, err
+		}
+		for _, s := range resp.Segments {
+			if err := ctx.Err(); err != nil {
+				return 0, Pagination{}, err
+			}
+			if err := fn(s); err != nil {
+				return 0, Pagination{}, err
+			}
+		}
+		if first {
+			first = false
+			for _, s := range resp.IntervalSegments {
+				if err := ctx.Err(); err != nil {
+					return 0, Pagination{}, err
+				}
+				if err := fn(s); err != nil {
+					return 0, Pagination{}, err
+				}
+			}
+		}
+		return len(resp.Segments), resp.Pagination, nil
+	})
+}
+
+// SearchAll возвращает не более max рейсов поиска, max <= 0 — все рейсы
+func SearchAll(ctx context.Context, c Client, req SearchRequest, max int) ([]Segment, error) {
+	var segments []Segment
+	err := SearchIter(ctx, c, req, func(s Segment) error {
+		segments = append(segments, s)
+		return collected(len(segments), max)
+	})
+	return segments, err
+}
+
+// SchedulesIter обходит все рейсы расписания станции, начиная с req.Offset и запрашивая страницы по req.Limit
+func SchedulesIter(ctx context.Context, c Client, req SchedulesRequest, fn func(Schedule) error) error {
+	return paginate(ctx, req.Offset, func(offset int) (int, Pagination, error) {
+		req.Offset = offset
+		resp, err := c.Schedules(ctx, req)
+		if err != nil {
+			return 0, Pagination{}, err
+		}
+		for _, s := range resp.Schedule {
+			if err := ctx.Err(); err != nil {
+				return 0, Pagination{}, err
+			}
+			if err := fn(s); err != nil {
+				return 0, Pagination{}, err
+			}
+		}
+		return len(resp.Schedule), resp.Pagination, nil
+	})
+}
+
+// SchedulesAll возвращает не более max рейсов расписания станции, max <= 0 — все рейсы
+func SchedulesAll(ctx context.Context, c Client, req SchedulesRequest, max int) ([]Schedule, error) {
+	var schedules []Schedule
+	err := SchedulesIter(ctx, c, req, func(s Schedule) error {
+		schedules = append(schedules, s)
+		return collected(len(schedules), max)
+	})
+	return schedules, err
+}
+
+// NearestStationsIter обходит все ближайшие станции, начиная с req.Offset и запрашивая страницы по req.Limit
+func NearestStationsIter(ctx context.Context, c Client, req NearestStationsRequest, fn func(NearestStation) error) error {
+	return paginate(ctx, req.Offset, func(offset int) (int, Pagination, error) {
+		req.Offset = offset
+		resp, err := c.NearestStations(ctx, req)
+		if err != nil {
+			return 0, Pagination{}, err
+		}
+		for _, s := range resp.Stations {
+			if err := ctx.Err(); err != nil {
+				return 0, Pagination{}, err
+			}
+			if err := fn(s); err != nil {
+				return 0, Pagination{}, err
+			}
+		}
+		return len(resp.Stations), resp.Pagination, nil
+	})
+}
+
+// NearestStationsAll возвращает не более max ближайших станций, max <= 0 — все станции
+func NearestStationsAll(ctx context.Context, c Client, req NearestStationsRequest, max int) ([]NearestStation, error) {
+	var stations []NearestStation
+	err := NearestStationsIter(ctx, c, req, func(s NearestStation) error {
+		stations = append(stations, s)
+		return collected(len(stations), max)
+	})
+	return stations, err
+}
+
+// paginate запрашивает страницы, пока не получит все элементы или page не вернет ошибку.
+// page возвращает число элементов на странице и информацию о постраничном выводе.
+func paginate(ctx context.Context, offset int, page func(offset int) (int, Pagination, error)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, p, err := page(offset)
+		if errors.Is(err, ErrStop) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		offset += n
+		if n == 0 || offset >= p.Total {
+			return nil
+		}
+	}
+}
+
+func collected(n, max int) error {
+	if max > 0 && n >= max {
+		return ErrStop
+	}
+	return nil
 }
@@ -0,0 +1,163 @@
+package yandex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPagedClient возвращает клиент, который отдает total рейсов расписания страницами по limit
+func newPagedClient(total int, requests *[]int) *client {
+	return newPagedListClient(total, "schedule", `{"thread": {"uid": "%d"}}`, "", requests)
+}
+
+// newPagedListClient возвращает клиент, который отдает total элементов списка field страницами по limit.
+// Элемент с номером i строится по шаблону item, extra добавляется в каждый ответ.
+func newPagedListClient(total int, field, item, extra string, requests *[]int) *client {
+	return newTestClient(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		*requests = append(*requests, offset)
+
+		var items []string
+		for i := offset; i < offset+limit && i < total; i++ {
+			items = append(items, fmt.Sprintf(item, i))
+		}
+		return okResponse(fmt.Sprintf(`{"pagination": {"total": %d, "limit": %d, "offset": %d}, %q: [%s]%s}`,
+			total, limit, offset, field, strings.Join(items, ","), extra)), nil
+	})
+}
+
+func TestSchedulesAll(t *testing.T) {
+	var requests []int
+	c := newPagedClient(25, &requests)
+
+	schedules, err := SchedulesAll(context.TODO(), c, SchedulesRequest{Station: "s1", Limit: 10}, 0)
+	require.NoError(t, err)
+	require.Len(t, schedules, 25)
+	for i, s := range schedules {
+		assert.Equal(t, strconv.Itoa(i), s.Thread.UID)
+	}
+	assert.Equal(t, []int{0, 10, 20}, requests)
+}
+
+func TestSchedulesAll_Max(t *testing.T) {
+	var requests []int
+	c := newPagedClient(25, &requests)
+
+	schedules, err := SchedulesAll(context.TODO(), c, SchedulesRequest{Station: "s1", Limit: 10, Offset: 5}, 12)
+	require.NoError(t, err)
+	require.Len(t, schedules, 12)
+	assert.Equal(t, "5", schedules[0].Thread.UID)
+	assert.Equal(t, []int{5, 15}, requests)
+}
+
+func TestSchedulesIter_Cancel(t *testing.T) {
+	var requests []int
+	var visited int
+	c := newPagedClient(25, &requests)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := SchedulesIter(ctx, c, SchedulesRequest{Station: "s1", Limit: 10}, func(s Schedule) error {
+		visited++
+		if s.Thread.UID == "3" {
+			cancel()
+		}
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 4, visited)
+	assert.Equal(t, []int{0}, requests)
+}
+
+func TestSchedulesIter_Error(t *testing.T) {
+	var requests []int
+	c := newPagedClient(25, &requests)
+	stop := fmt.Errorf("stop")
+
+	err := SchedulesIter(context.TODO(), c, SchedulesRequest{Station: "s1", Limit: 10}, func(s Schedule) error {
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []int{0}, requests)
+}
+
+func TestSchedulesIter_WrappedStop(t *testing.T) {
+	var requests []int
+	var visited int
+	c := newPagedClient(25, &requests)
+
+	err := SchedulesIter(context.TODO(), c, SchedulesRequest{Station: "s1", Limit: 10}, func(s Schedule) error {
+		visited++
+		return fmt.Errorf("schedule %s: %w", s.Thread.UID, ErrStop)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, visited)
+	assert.Equal(t, []int{0}, requests)
+}
+
+func TestSearchAll(t *testing.T) {
+	var requests []int
+	c := newPagedListClient(15, "segments", `{"thread": {"uid": "%d"}}`, `, "interval_segments": [{"thread": {"uid": "interval"}}]`, &requests)
+
+	segments, err := SearchAll(context.TODO(), c, SearchRequest{From: "c213", To: "c2", Limit: 10}, 0)
+	require.NoError(t, err)
+	require.Len(t, segments, 16)
+	assert.Equal(t, "9", segments[9].Thread.UID)
+	assert.Equal(t, "interval", segments[10].Thread.UID)
+	assert.Equal(t, "14", segments[15].Thread.UID)
+	assert.Equal(t, []int{0, 10}, requests)
+}
+
+func TestSearchIter_Cancel(t *testing.T) {
+	var requests []int
+	var visited int
+	c := newPagedListClient(25, "segments", `{"thread": {"uid": "%d"}}`, "", &requests)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := SearchIter(ctx, c, SearchRequest{From: "c213", To: "c2", Limit: 10}, func(s Segment) error {
+		visited++
+		if s.Thread.UID == "3" {
+			cancel()
+		}
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 4, visited)
+	assert.Equal(t, []int{0}, requests)
+}
+
+func TestNearestStationsAll(t *testing.T) {
+	var requests []int
+	c := newPagedListClient(7, "stations", `{"code": "s%d"}`, "", &requests)
+
+	stations, err := NearestStationsAll(context.TODO(), c, NearestStationsRequest{Lat: 55.75, Lng: 37.62, Limit: 3, Offset: 2}, 4)
+	require.NoError(t, err)
+	require.Len(t, stations, 4)
+	assert.Equal(t, "s2", stations[0].Code)
+	assert.Equal(t, "s5", stations[3].Code)
+	assert.Equal(t, []int{2, 5}, requests)
+}
+
+func TestNearestStationsIter_Cancel(t *testing.T) {
+	var requests []int
+	var visited int
+	c := newPagedListClient(7, "stations", `{"code": "s%d"}`, "", &requests)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := NearestStationsIter(ctx, c, NearestStationsRequest{Lat: 55.75, Lng: 37.62, Limit: 5}, func(s NearestStation) error {
+		visited++
+		cancel()
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, visited)
+	assert.Equal(t, []int{0}, requests)
+}
@@ -0,0 +1,10 @@
+package yandex
+
+// Пункт: город или станция
+type Point struct {
+	Code         string `json:"code" xml:"code"`
+	Title        string `json:"title" xml:"title"`
+	PopularTitle string `json:"popular_title" xml:"popular_title"`
+	ShortTitle   string `json:"short_title" xml:"short_title"`
+	Type         string `json:"type" xml:"type"` // station или settlement
+}
@@ -0,0 +1,220 @@
+package yandex
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets границы гистограммы времени запроса в секундах
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics хранит метрики в памяти и отдает их в текстовом формате Prometheus
+type PrometheusMetrics struct {
+	mu         sync.Mutex
+	buckets    []float64
+	requests   map[[2]string]uint64 // endpoint, status
+	latency    map[string]*histogram
+	cacheHits  map[string]uint64
+	retries    map[string]uint64
+	rateLimits map[[3]string]uint64 // endpoint, index, key
+	keys       map[int]keyQuota
+}
+
+type histogram struct {
+	counts []uint64 // Число наблюдений не больше соответствующей границы
+	sum    float64
+	count  uint64
+}
+
+type keyQuota struct {
+	key             string
+	used, remaining int
+}
+
+// NewPrometheusMetrics создает метрики с границами гистограммы buckets, по умолчанию DefaultLatencyBuckets
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &PrometheusMetrics{
+		buckets:    buckets,
+		requests:   make(map[[2]string]uint64),
+		latency:    make(map[string]*histogram),
+		cacheHits:  make(map[string]uint64),
+		retries:    make(map[string]uint64),
+		rateLimits: make(map[[3]string]uint64),
+		keys:       make(map[int]keyQuota),
+	}
+}
+
+func (m *PrometheusMetrics) ObserveRequest(endpoint string, status int, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[[2]string{endpoint, strconv.Itoa(status)}]++
+
+	h, ok := m.latency[endpoint]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[endpoint] = h
+	}
+	seconds := latency.Seconds()
+	for i, b := range m.buckets {
+		if seconds <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (m *PrometheusMetrics) ObserveCacheHit(endpoint string) {
+	m.mu.Lock()
+	m.cacheHits[endpoint]++
+	m.mu.Unlock()
+}
+
+func (m *PrometheusMetrics) ObserveRetry(endpoint string) {
+	m.mu.Lock()
+	m.retries[endpoint]++
+	m.mu.Unlock()
+}
+
+func (m *PrometheusMetrics) ObserveRateLimit(endpoint string, index int, key string) {
+	m.mu.Lock()
+	m.rateLimits[[3]string{endpoint, strconv.Itoa(index), key}]++
+	m.mu.Unlock()
+}
+
+func (m *PrometheusMetrics) SetKeyQuota(index int, key string, used, remaining int) {
+	m.mu.Lock()
+	m.keys[index] = keyQuota{key: key, used: used, remaining: remaining}
+	m.mu.Unlock()
+}
+
+// ServeHTTP отдает метрики в текстовом формате Prometheus
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	m.write(bw)
+	bw.Flush()
+}
+
+func (m *PrometheusMetrics) write(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP rasp_requests_total HTTP requests to Yandex Rasp API by endpoint and status code, 0 when no response.")
+	fmt.Fprintln(w, "# TYPE rasp_requests_total counter")
+	requests := make([][2]string, 0, len(m.requests))
+	for k := range m.requests {
+		requests = append(requests, k)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i][0] != requests[j][0] {
+			return requests[i][0] < requests[j][0]
+		}
+		return requests[i][1] < requests[j][1]
+	})
+	for _, k := range requests {
+		fmt.Fprintf(w, "rasp_requests_total{endpoint=%s,status=%s} %d\n", quote(k[0]), quote(k[1]), m.requests[k])
+	}
+
+	fmt.Fprintln(w, "# HELP rasp_request_duration_seconds HTTP request latency by endpoint.")
+	fmt.Fprintln(w, "# TYPE rasp_request_duration_seconds histogram")
+	for _, endpoint := range sortedKeys(m.latency) {
+		h := m.latency[endpoint]
+		for i, b := range m.buckets {
+			fmt.Fprintf(w, "rasp_request_duration_seconds_bucket{endpoint=%s,le=%s} %d\n", quote(endpoint), quote(formatFloat(b)), h.counts[i])
+		}
+		fmt.Fprintf(w, "rasp_request_duration_seconds_bucket{endpoint=%s,le=\"+Inf\"} %d\n", quote(endpoint), h.count)
+		fmt.Fprintf(w, "rasp_request_duration_seconds_sum{endpoint=%s} %s\n", quote(endpoint), formatFloat(h.sum))
+		fmt.Fprintf(w, "rasp_request_duration_seconds_count{endpoint=%s} %d\n", quote(endpoint), h.count)
+	}
+
+	fmt.Fprintln(w, "# HELP rasp_cache_hits_total Responses served from the cache without an HTTP request.")
+	fmt.Fprintln(w, "# TYPE rasp_cache_hits_total counter")
+	writeCounters(w, "rasp_cache_hits_total", m.cacheHits)
+
+	fmt.Fprintln(w, "# HELP rasp_retries_total Repeated HTTP requests after an error or a 429 response.")
+	fmt.Fprintln(w, "# TYPE rasp_retries_total counter")
+	writeCounters(w, "rasp_retries_total", m.retries)
+
+	fmt.Fprintln(w, "# HELP rasp_rate_limited_total 429 responses by endpoint and API key.")
+	fmt.Fprintln(w, "# TYPE rasp_rate_limited_total counter")
+	limits := make([][3]string, 0, len(m.rateLimits))
+	for k := range m.rateLimits {
+		limits = append(limits, k)
+	}
+	sort.Slice(limits, func(i, j int) bool {
+		for n := range limits[i] {
+			if limits[i][n] != limits[j][n] {
+				return limits[i][n] < limits[j][n]
+			}
+		}
+		return false
+	})
+	for _, k := range limits {
+		fmt.Fprintf(w, "rasp_rate_limited_total{endpoint=%s,key_index=%s,key=%s} %d\n", quote(k[0]), quote(k[1]), quote(k[2]), m.rateLimits[k])
+	}
+
+	indexes := make([]int, 0, len(m.keys))
+	for i := range m.keys {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	// key_index — номер ключа в текущем пуле клиента, key — маска ключа для различения после замены пула
+	fmt.Fprintln(w, "# HELP rasp_key_used_requests Requests made with an API key during the current Moscow day.")
+	fmt.Fprintln(w, "# TYPE rasp_key_used_requests gauge")
+	for _, i := range indexes {
+		fmt.Fprintf(w, "rasp_key_used_requests{key_index=\"%d\",key=%s} %d\n", i, quote(m.keys[i].key), m.keys[i].used)
+	}
+
+	fmt.Fprintln(w, "# HELP rasp_key_remaining_requests Remaining daily quota of an API key. Keys without a limit are omitted.")
+	fmt.Fprintln(w, "# TYPE rasp_key_remaining_requests gauge")
+	for _, i := range indexes {
+		if m.keys[i].remaining >= 0 {
+			fmt.Fprintf(w, "rasp_key_remaining_requests{key_index=\"%d\",key=%s} %d\n", i, quote(m.keys[i].key), m.keys[i].remaining)
+		}
+	}
+}
+
+// writeCounters пишет счетчики name по endpoint в порядке endpoint
+func writeCounters(w *bufio.Writer, name string, counters map[string]uint64) {
+	endpoints := make([]string, 0, len(counters))
+	for k := range counters {
+		endpoints = append(endpoints, k)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(w, "%s{endpoint=%s} %d\n", name, quote(endpoint), counters[endpoint])
+	}
+}
+
+func sortedKeys(m map[string]*histogram) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quote экранирует значение метки
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,101 @@
+package yandex
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	var calls int
+	metrics := NewPrometheusMetrics(0.5, 1)
+	c, err := New(
+		WithKeyPool(NewKeyPool([]string{secretKey, "second-key-0000"}, KeyPoolConfig{DailyLimit: 100})),
+		WithRetry(RetryPolicy{BaseDelay: 1}),
+		WithMetrics(metrics),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			switch calls {
+			case 1:
+				return statusResponse(http.StatusTooManyRequests), nil
+			case 2:
+				return statusResponse(http.StatusBadGateway), nil
+			}
+			return okResponse(`{"copyright": {}}`), nil
+		})),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Copyright(WithoutCache(context.TODO()))
+	require.NoError(t, err)
+	metrics.ObserveRequest("search", 200, 700*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+	out := string(body)
+
+	assert.NotContains(t, out, secretKey)
+	for _, line := range []string{
+		`rasp_requests_total{endpoint="copyright",status="200"} 1`,
+		`rasp_requests_total{endpoint="copyright",status="429"} 1`,
+		`rasp_requests_total{endpoint="copyright",status="502"} 1`,
+		`rasp_request_duration_seconds_bucket{endpoint="copyright",le="0.5"} 3`,
+		`rasp_request_duration_seconds_bucket{endpoint="search",le="0.5"} 0`,
+		`rasp_request_duration_seconds_bucket{endpoint="search",le="1"} 1`,
+		`rasp_request_duration_seconds_bucket{endpoint="search",le="+Inf"} 1`,
+		`rasp_request_duration_seconds_sum{endpoint="search"} 0.7`,
+		`rasp_request_duration_seconds_count{endpoint="search"} 1`,
+		`rasp_retries_total{endpoint="copyright"} 2`,
+		`rasp_rate_limited_total{endpoint="copyright",key_index="0",key="` + maskKey(secretKey) + `"} 1`,
+		`rasp_key_used_requests{key_index="1",key="seco****"} 2`,
+		`rasp_key_remaining_requests{key_index="0",key="` + maskKey(secretKey) + `"} 99`,
+		`rasp_key_remaining_requests{key_index="1",key="seco****"} 98`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+}
+
+func TestPrometheusMetrics_CacheHit(t *testing.T) {
+	var calls int
+	metrics := NewPrometheusMetrics()
+	c, err := New(
+		WithKeys(secretKey),
+		WithCache(NewLRUCache(10), nil),
+		WithMetrics(metrics),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return okResponse(`{"copyright": {}}`), nil
+		})),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = c.Copyright(context.TODO())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `rasp_requests_total{endpoint="copyright",status="200"} 1`+"\n")
+	assert.Contains(t, rec.Body.String(), `rasp_cache_hits_total{endpoint="copyright"} 2`+"\n")
+}
+
+func TestPrometheusMetrics_Unlimited(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	metrics.SetKeyQuota(0, "abcd****", 5, -1)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `rasp_key_used_requests{key_index="0",key="abcd****"} 5`)
+	assert.NotContains(t, rec.Body.String(), `rasp_key_remaining_requests{`)
+}
@@ -0,0 +1,63 @@
+package yandex
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// redactedError ошибка, в тексте которой API ключи заменены маской.
+// Исходная ошибка не раскрывается, но доступна для errors.Is и errors.As.
+// APIError и *url.Error в цепочке очищаются от ключей до оборачивания.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+func (e *redactedError) As(target interface{}) bool {
+	return errors.As(e.err, target)
+}
+
+// redact заменяет ключи пула маской
+func (p *KeyPool) redact(s string) string {
+	if p == nil {
+		return s
+	}
+	for _, k := range p.keys {
+		if k.key != "" {
+			s = strings.Replace(s, k.key, maskKey(k.key), -1)
+		}
+	}
+	return s
+}
+
+// redactError возвращает err без ключей пула в тексте
+func (p *KeyPool) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.Text = p.redact(apiErr.Text)
+		apiErr.Request = p.redact(apiErr.Request)
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = p.redact(urlErr.URL)
+	}
+
+	msg := err.Error()
+	if redacted := p.redact(msg); redacted != msg {
+		return &redactedError{msg: redacted, err: err}
+	}
+	return err
+}
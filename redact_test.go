@@ -0,0 +1,147 @@
+package yandex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secretKey = "7e3b1a90-52d4-4c1f-9a8e-0123456789ab"
+
+func TestClient_KeyInHeader(t *testing.T) {
+	var got *http.Request
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		got = req
+		return okResponse(`{}`), nil
+	})
+	c.pool = NewKeyPool([]string{secretKey}, KeyPoolConfig{})
+
+	_, err := c.Copyright(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, secretKey, got.Header.Get("Authorization"))
+	assert.NotContains(t, got.URL.String(), secretKey)
+}
+
+func TestClient_NoKeyLeak(t *testing.T) {
+	responses := []func() (*http.Response, error){
+		func() (*http.Response, error) {
+			return nil, fmt.Errorf("dial tcp: key %s rejected", secretKey)
+		},
+		func() (*http.Response, error) {
+			resp := okResponse(`{"error": {"text": "Ключ ` + secretKey + ` заблокирован", "request": "https://api.rasp.yandex.net/v3.0/search/?apikey=` + secretKey + `"}}`)
+			resp.StatusCode = http.StatusForbidden
+			return resp, nil
+		},
+		func() (*http.Response, error) {
+			resp := okResponse("limit exceeded for " + secretKey)
+			resp.StatusCode = http.StatusTooManyRequests
+			return resp, nil
+		},
+		func() (*http.Response, error) {
+			return okResponse(`{"copyright": ` + secretKey + `}`), nil
+		},
+	}
+
+	for i, respond := range responses {
+		var calls []*Call
+		log := &memoryLogger{}
+		tracer := &memoryTracer{}
+		pool := NewKeyPool([]string{secretKey}, KeyPoolConfig{})
+		cl, err := New(
+			WithKeyPool(pool),
+			WithRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: 1}),
+			WithCache(NewLRUCache(10), nil),
+			WithLogger(log, LogConfig{Level: LevelDebug, SlowRequest: 1}),
+			WithTracer(tracer),
+			WithMiddleware(func(next Doer) Doer {
+				return DoerFunc(func(ctx context.Context, call *Call) error {
+					calls = append(calls, call)
+					return next.Do(ctx, call)
+				})
+			}),
+			WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := respond()
+				if resp != nil {
+					resp.Request = req
+				}
+				return resp, err
+			})),
+		)
+		require.NoError(t, err)
+		c := cl.(*client)
+
+		_, err = c.Copyright(context.TODO())
+		require.Error(t, err, "case %d", i)
+		assert.NotContains(t, err.Error(), secretKey, "case %d", i)
+		assert.NotContains(t, fmt.Sprintf("%+v %#v", err, err), secretKey, "case %d", i)
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			assert.NotContains(t, apiErr.Text, secretKey, "case %d", i)
+			assert.NotContains(t, apiErr.Request, secretKey, "case %d", i)
+		}
+
+		assert.NotContains(t, fmt.Sprintf("%v %+v %#v", c.pool, c.pool, c.pool), secretKey, "case %d", i)
+		assert.NotContains(t, fmt.Sprintf("%+v", c.cfg), secretKey, "case %d", i)
+		assert.NotContains(t, fmt.Sprintf("%+v", c.pool.Status()), secretKey, "case %d", i)
+
+		// Значения, которые видят middleware, журнал и трассировка
+		var captured []string
+		require.Len(t, calls, 1, "case %d", i)
+		for _, call := range calls {
+			captured = append(captured, fmt.Sprintf("%+v %s", *call, call.URL.String()))
+			for _, a := range call.Attempts {
+				captured = append(captured, fmt.Sprintf("%v %+v %+v", a.Err, a.Request.URL, a.Request.Header))
+				if a.Response != nil {
+					captured = append(captured, fmt.Sprintf("%+v %+v", a.Response.Header, a.Response.Request.Header))
+				}
+			}
+		}
+		require.NotEmpty(t, log.entries, "case %d", i)
+		for _, e := range log.entries {
+			captured = append(captured, fmt.Sprintf("%s %+v", e.msg, e.attrs))
+		}
+		require.Len(t, tracer.spans, 1, "case %d", i)
+		for _, span := range tracer.spans {
+			captured = append(captured, fmt.Sprintf("%s %v %+v", span.name, span.err, span.attrs))
+		}
+		for _, v := range captured {
+			assert.NotContains(t, v, secretKey, "case %d", i)
+		}
+	}
+}
+
+func TestRedactedError_Is(t *testing.T) {
+	p := NewKeyPool([]string{secretKey}, KeyPoolConfig{})
+	err := p.redactError(fmt.Errorf("%w: %s", context.DeadlineExceeded, secretKey))
+
+	assert.NotContains(t, err.Error(), secretKey)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRedactedError_As(t *testing.T) {
+	p := NewKeyPool([]string{secretKey}, KeyPoolConfig{})
+	err := p.redactError(fmt.Errorf("search %s: %w", secretKey, &url.Error{
+		Op:  "Get",
+		URL: "https://api.rasp.yandex.net/v3.0/search/?apikey=" + secretKey,
+		Err: &net.DNSError{Err: "i/o timeout", Name: "api.rasp.yandex.net", IsTimeout: true},
+	}))
+	assert.NotContains(t, err.Error(), secretKey)
+
+	var urlErr *url.Error
+	require.True(t, errors.As(err, &urlErr))
+	assert.NotContains(t, urlErr.URL, secretKey)
+	assert.NotContains(t, urlErr.Error(), secretKey)
+
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr))
+	assert.True(t, netErr.Timeout())
+	assert.NotContains(t, netErr.Error(), secretKey)
+}
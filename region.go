@@ -1,7 +1,8 @@
 package yandex
 
-// Регион страны
+// Регион страны. Заголовок идет в JSON до списка городов, см. Country.
 type Region struct {
-	Settlements []Settlement `json:"settlements"`
-	Name        string       `json:"title"`
+	Name        string       `json:"title" xml:"title"`
+	Codes       Codes        `json:"codes" xml:"codes"`
+	Settlements []Settlement `json:"settlements" xml:"settlements>settlement"`
 }
@@ -0,0 +1,63 @@
+package yandex
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy политика повтора запросов при ответах 5xx и сетевых ошибках.
+// Ответ 429 повторяется сразу со следующим ключом пула, не больше одного раза на ключ, и не учитывается в MaxAttempts.
+type RetryPolicy struct {
+	MaxAttempts int           // Максимальное число попыток, по умолчанию 3. Значение 1 отключает повторы
+	BaseDelay   time.Duration // Задержка перед первым повтором, по умолчанию 200ms
+	MaxDelay    time.Duration // Максимальная задержка, по умолчанию 5s
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	return p
+}
+
+// backoff возвращает задержку перед повтором с экспоненциальным ростом и случайным разбросом
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// sleep ждет d или отмены контекста. Возвращает false, если дедлайн контекста наступит раньше.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,155 @@
+package yandex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statusResponse(code int) *http.Response {
+	resp := okResponse(`{"error": {}}`)
+	resp.StatusCode = code
+	return resp
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}.withDefaults()
+
+	for attempt, limit := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		limit *= time.Millisecond
+		d := p.backoff(attempt + 1)
+		assert.True(t, d >= limit/2 && d <= limit, "attempt %d: %s", attempt+1, d)
+	}
+}
+
+func TestClient_RetryServerError(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		switch calls {
+		case 1:
+			return statusResponse(http.StatusBadGateway), nil
+		case 2:
+			return nil, errors.New("connection reset")
+		}
+		return okResponse(`{"copyright": {"text": "ok"}}`), nil
+	})
+	c.cfg.Retry = RetryPolicy{BaseDelay: time.Millisecond}
+
+	resp, err := c.Copyright(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Copyright.Text)
+	assert.Equal(t, 3, calls)
+}
+
+func TestClient_RetryMaxAttempts(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusServiceUnavailable), nil
+	})
+	c.cfg.Retry = RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+
+	_, err := c.Copyright(context.TODO())
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestClient_RetryNoRetryOnClientError(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusBadRequest), nil
+	})
+
+	_, err := c.Copyright(context.TODO())
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestClient_RetryTooManyRequestsNextKey(t *testing.T) {
+	var keys []string
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		key := req.Header.Get("Authorization")
+		keys = append(keys, key)
+		if key == "first" {
+			return statusResponse(http.StatusTooManyRequests), nil
+		}
+		return okResponse(`{}`), nil
+	})
+	c.pool = NewKeyPool([]string{"first", "second"}, KeyPoolConfig{})
+
+	_, err := c.Copyright(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, keys)
+
+	_, err = c.Copyright(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "second"}, keys)
+}
+
+func TestClient_RetryTooManyRequestsPoolExhausted(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusTooManyRequests), nil
+	})
+	c.pool = NewKeyPool([]string{"first", "second"}, KeyPoolConfig{})
+
+	_, err := c.Copyright(context.TODO())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+	assert.Equal(t, 2, calls)
+}
+
+func TestClient_RetryTooManyRequestsShortCooldown(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusTooManyRequests), nil
+	})
+	c.pool = NewKeyPool([]string{"first", "second"}, KeyPoolConfig{Cooldown: time.Millisecond})
+	c.cfg.Retry = RetryPolicy{BaseDelay: time.Second}
+
+	start := time.Now()
+	_, err := c.Copyright(context.TODO())
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.Equal(t, 2, calls)
+	assert.True(t, time.Since(start) < 100*time.Millisecond, "429 retry must not wait")
+
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	c = newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		cancel()
+		return statusResponse(http.StatusTooManyRequests), nil
+	})
+	c.pool = NewKeyPool([]string{"first", "second"}, KeyPoolConfig{})
+
+	_, err = c.Copyright(ctx)
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.Equal(t, 1, calls)
+}
+
+func TestClient_RetryContextDeadline(t *testing.T) {
+	var calls int
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusInternalServerError), nil
+	})
+	c.cfg.Retry = RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Copyright(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+}
@@ -1,13 +1,33 @@
 package yandex
 
+import "time"
+
 type Schedule struct {
-	ExceptDays string    `json:"except_days"` // Дни, в которые нитка не курсирует (даже если они входят в множество, описанное элементом days). Format "6, 7, 8, 9, 13, 14 февраля"
-	Arrival    *string `json:"arrival"`     // Время прибытия
-	Thread     Thread    `json:"thread"`      // Информация о нитке
-	IsFuzzy    bool      `json:"is_fuzzy"`    // Признак неточности времени отправления и времени прибытия. Возможные значения: true — время прибытия и время отправления указаны неточно; false — время прибытия и время отправления указан точно.
-	Days       string    `json:"days"`        // Дни курсирования нитки
-	Stops      string    `json:"stops"`       // Станции следования рейса, на которых совершается остановка. Описывается в свободной форме. Например, значение везде значит, что остановка совершается на всех станциях следования. Пустая строка значит, что нитка нигде не останавливается между начальной и конечной станциями.
-	Departure  *string `json:"departure"`   // Время отправления
-	Terminal   string    `json:"terminal"`    // Терминал аэропорта (например, «D»). Принимает значение null, если информации о терминале нет.
-	Platform   string    `json:"platform"`    // Платформа или путь, с которого отправляется рейс (например, «3 путь»). Пустая строка значит, что информации о платформе или пути нет.
+	ExceptDays string  `json:"except_days" xml:"except_days"` // Дни, в которые нитка не курсирует (даже если они входят в множество, описанное элементом days). Format "6, 7, 8, 9, 13, 14 февраля"
+	Arrival    *string `json:"arrival" xml:"arrival"`         // Время прибытия
+	Thread     Thread  `json:"thread" xml:"thread"`           // Информация о нитке
+	IsFuzzy    bool    `json:"is_fuzzy" xml:"is_fuzzy"`       // Признак неточности времени отправления и времени прибытия. Возможные значения: true — время прибытия и время отправления указаны неточно; false — время прибытия и время отправления указан точно.
+	Days       string  `json:"days" xml:"days"`               // Дни курсирования нитки
+	Stops      string  `json:"stops" xml:"stops"`             // Станции следования рейса, на которых совершается остановка. Описывается в свободной форме. Например, значение везде значит, что остановка совершается на всех станциях следования. Пустая строка значит, что нитка нигде не останавливается между начальной и конечной станциями.
+	Departure  *string `json:"departure" xml:"departure"`     // Время отправления
+	Terminal   string  `json:"terminal" xml:"terminal"`       // Терминал аэропорта (например, «D»). Принимает значение null, если информации о терминале нет.
+	Platform   string  `json:"platform" xml:"platform"`       // Платформа или путь, с которого отправляется рейс (например, «3 путь»). Пустая строка значит, что информации о платформе или пути нет.
+}
+
+// ArrivalTime возвращает время прибытия в часовом поясе станции, время без смещения считается ошибкой.
+// Если прибытия нет (рейс начинается на станции), возвращается нулевое время.
+func (s *Schedule) ArrivalTime() (time.Time, error) {
+	if s.Arrival == nil {
+		return time.Time{}, nil
+	}
+	return ParseTime(*s.Arrival, nil)
+}
+
+// DepartureTime возвращает время отправления в часовом поясе станции.
+// Если отправления нет (рейс заканчивается на станции), возвращается нулевое время.
+func (s *Schedule) DepartureTime() (time.Time, error) {
+	if s.Departure == nil {
+		return time.Time{}, nil
+	}
+	return ParseTime(*s.Departure, nil)
 }
@@ -1,19 +1,84 @@
 package yandex
 
+import "time"
+
 type Segment struct {
-	Arrival           string      `json:"arrival"`
-	From              Station     `json:"from"`
-	Thread            Thread      `json:"thread"`
-	DeparturePlatform string      `json:"departure_platform"`
-	Departure         string      `json:"departure"`
-	Stops             string      `json:"stops"`
-	DepartureTerminal interface{} `json:"departure_terminal"`
-	To                Station     `json:"to"`
-	HasTransfers      bool        `json:"has_transfers"`
-	TicketsInfo       interface{} `json:"tickets_info"`
-	Duration          float64     `json:"duration"`
-	ArrivalTerminal   string      `json:"arrival_terminal"`
-	StartDate         string      `json:"start_date"`
-	ArrivalPlatform   string      `json:"arrival_platform"`
-	Days string `json:"days"`
+	Arrival           string       `json:"arrival" xml:"arrival"`
+	From              Station      `json:"from" xml:"from"`
+	Thread            Thread       `json:"thread" xml:"thread"`
+	DeparturePlatform string       `json:"departure_platform" xml:"departure_platform"`
+	Departure         string       `json:"departure" xml:"departure"`
+	Stops             string       `json:"stops" xml:"stops"`
+	DepartureTerminal string       `json:"departure_terminal" xml:"departure_terminal"`
+	To                Station      `json:"to" xml:"to"`
+	HasTransfers      bool         `json:"has_transfers" xml:"has_transfers"`
+	TicketsInfo       *TicketsInfo `json:"tickets_info" xml:"tickets_info"`
+	Duration          float64      `json:"duration" xml:"duration"`
+	ArrivalTerminal   string       `json:"arrival_terminal" xml:"arrival_terminal"`
+	StartDate         string       `json:"start_date" xml:"start_date"`
+	ArrivalPlatform   string       `json:"arrival_platform" xml:"arrival_platform"`
+	Days              string       `json:"days" xml:"days"`
+	Interval          *Interval    `json:"interval" xml:"interval"` // Интервал движения для интервальных рейсов
+
+	// Поля маршрута с пересадками (has_transfers = true)
+	DepartureFrom  *Station         `json:"departure_from" xml:"departure_from"`                  // Станция отправления маршрута
+	ArrivalTo      *Station         `json:"arrival_to" xml:"arrival_to"`                          // Станция прибытия маршрута
+	TransportTypes []TransportType  `json:"transport_types" xml:"transport_types>transport_type"` // Типы транспорта на участках маршрута
+	Transfers      []Point          `json:"transfers" xml:"transfers>transfer"`                   // Пункты пересадок
+	Details        []TransferDetail `json:"details" xml:"details>detail"`                         // Участки маршрута и пересадки между ними в порядке следования
+}
+
+// DepartureTime возвращает время отправления в часовом поясе станции отправления.
+// Время без смещения считается ошибкой, см. ParseTime.
+func (s *Segment) DepartureTime() (time.Time, error) {
+	return ParseTime(s.Departure, nil)
+}
+
+// ArrivalTime возвращает время прибытия в часовом поясе станции прибытия, см. DepartureTime
+func (s *Segment) ArrivalTime() (time.Time, error) {
+	return ParseTime(s.Arrival, nil)
+}
+
+// TravelDuration возвращает время в пути, для пересадки — время ожидания
+func (s *Segment) TravelDuration() time.Duration {
+	return seconds(s.Duration)
+}
+
+// Legs возвращает участки маршрута с пересадками без самих пересадок
+func (s *Segment) Legs() []Segment {
+	var legs []Segment
+	for _, d := range s.Details {
+		if !d.IsTransfer {
+			legs = append(legs, d.Segment)
+		}
+	}
+	return legs
+}
+
+// TransferDetails возвращает пересадки маршрута
+func (s *Segment) TransferDetails() []TransferDetail {
+	var transfers []TransferDetail
+	for _, d := range s.Details {
+		if d.IsTransfer {
+			transfers = append(transfers, d)
+		}
+	}
+	return transfers
+}
+
+// Элемент маршрута с пересадками: участок пути или пересадка.
+// Для пересадки Duration содержит время ожидания между участками в секундах.
+type TransferDetail struct {
+	Segment
+	IsTransfer    bool     `json:"is_transfer" xml:"is_transfer"`       // Признак пересадки
+	TransferFrom  *Station `json:"transfer_from" xml:"transfer_from"`   // Станция прибытия предыдущего участка
+	TransferTo    *Station `json:"transfer_to" xml:"transfer_to"`       // Станция отправления следующего участка
+	TransferPoint *Point   `json:"transfer_point" xml:"transfer_point"` // Пункт пересадки
+}
+
+// Интервал движения рейса, который ходит с заданной частотой, а не по точному расписанию
+type Interval struct {
+	Density   string `json:"density" xml:"density"`       // Частота движения, например «раз в 10 минут»
+	BeginTime string `json:"begin_time" xml:"begin_time"` // Время начала движения
+	EndTime   string `json:"end_time" xml:"end_time"`     // Время окончания движения
 }
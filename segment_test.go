@@ -0,0 +1,45 @@
+package yandex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const transferSegmentJSON = `{
+	"has_transfers": true,
+	"departure": "2020-01-10T06:00:00+03:00",
+	"arrival": "2020-01-10T14:30:00+03:00",
+	"duration": 30600,
+	"transport_types": ["suburban", "train"],
+	"departure_from": {"code": "s2006004", "title": "Москва"},
+	"arrival_to": {"code": "s9602494", "title": "Санкт-Петербург"},
+	"transfers": [{"code": "c10", "title": "Тверь", "type": "settlement"}],
+	"details": [
+		{"thread": {"uid": "6001_0_9600212_g20_4"}, "from": {"code": "s2006004"}, "to": {"code": "s9603093"}, "duration": 9000},
+		{"is_transfer": true, "duration": 3600, "transfer_from": {"code": "s9603093"}, "transfer_to": {"code": "s9603093"}, "transfer_point": {"code": "c10", "title": "Тверь"}},
+		{"thread": {"uid": "020A_1_2"}, "from": {"code": "s9603093"}, "to": {"code": "s9602494"}, "duration": 18000}
+	]
+}`
+
+func TestSegment_Transfers(t *testing.T) {
+	var s Segment
+	require.NoError(t, json.Unmarshal([]byte(transferSegmentJSON), &s))
+
+	assert.True(t, s.HasTransfers)
+	assert.Equal(t, "s2006004", s.DepartureFrom.Code)
+	assert.Equal(t, "s9602494", s.ArrivalTo.Code)
+	assert.Equal(t, []TransportType{Suburban, Train}, s.TransportTypes)
+
+	legs := s.Legs()
+	require.Len(t, legs, 2)
+	assert.Equal(t, "6001_0_9600212_g20_4", legs[0].Thread.UID)
+	assert.Equal(t, "020A_1_2", legs[1].Thread.UID)
+
+	transfers := s.TransferDetails()
+	require.Len(t, transfers, 1)
+	assert.Equal(t, float64(3600), transfers[0].Duration)
+	assert.Equal(t, "Тверь", transfers[0].TransferPoint.Title)
+}
@@ -1,7 +1,7 @@
 package yandex
 
 type Settlement struct {
-	Name     string      `json:"title"`
-	Codes    interface{} `json:"codes"`
-	Stations []Station   `json:"stations"`
+	Name     string    `json:"title" xml:"title"`
+	Codes    Codes     `json:"codes" xml:"codes"`
+	Stations []Station `json:"stations" xml:"stations>station"`
 }
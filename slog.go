@@ -0,0 +1,29 @@
+//go:build go1.21
+// +build go1.21
+
+package yandex
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger возвращает Logger, передающий события в l
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l: l}
+}
+
+func (s slogLogger) Log(ctx context.Context, level Level, msg string, attrs ...Attribute) {
+	if !s.l.Enabled(ctx, slog.Level(level)) {
+		return
+	}
+	slogAttrs := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		slogAttrs = append(slogAttrs, slog.Any(a.Key, a.Value))
+	}
+	s.l.LogAttrs(ctx, slog.Level(level), msg, slogAttrs...)
+}
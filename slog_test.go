@@ -0,0 +1,30 @@
+//go:build go1.21
+// +build go1.21
+
+package yandex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	l.Log(context.TODO(), LevelDebug, "cache hit", Attribute{Key: "endpoint", Value: "search"})
+	l.Log(context.TODO(), LevelWarn, "retry request", Attribute{Key: "endpoint", Value: "search"}, Attribute{Key: "attempt", Value: 2})
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "retry request", record["msg"])
+	assert.Equal(t, "search", record["endpoint"])
+	assert.Equal(t, float64(2), record["attempt"])
+}
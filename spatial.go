@@ -0,0 +1,329 @@
+package yandex
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"sort"
+)
+
+// Средний радиус Земли в километрах
+const earthRadius = 6371.0088
+
+// SpatialIndex пространственный индекс станций и населенных пунктов справочника (k-d дерево).
+// Отвечает на запросы nearest_stations и nearest_settlement без обращения к API.
+// После создания индекс не изменяется и безопасен для конкурентного использования.
+type SpatialIndex struct {
+	stations    kdTree
+	settlements kdTree
+	directory   []DirectoryStation
+	cities      []NearestCityResponse
+}
+
+// SpatialFilter отбор станций при поиске. Пустые поля не ограничивают поиск.
+type SpatialFilter struct {
+	TransportTypes []TransportType
+	StationTypes   []string
+}
+
+func (f SpatialFilter) match(s *DirectoryStation) bool {
+	if len(f.TransportTypes) > 0 {
+		ok := false
+		for _, t := range f.TransportTypes {
+			ok = ok || t.String() == s.TransportType
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(f.StationTypes) > 0 {
+		ok := false
+		for _, t := range f.StationTypes {
+			ok = ok || t == s.Type
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSpatialIndex строит индекс по станциям справочника с известными координатами.
+// Координаты населенного пункта — центр его станций.
+func NewSpatialIndex(d *StationDirectory) *SpatialIndex {
+	idx := &SpatialIndex{}
+
+	type centroid struct {
+		lat, lng float64
+		n        int
+		city     NearestCityResponse
+	}
+	var order []string
+	centroids := make(map[string]*centroid)
+
+	var points []kdPoint
+	for _, s := range d.stations {
+		if s.Lat == 0 && s.Lng == 0 {
+			continue
+		}
+		points = append(points, kdPoint{pos: toCartesian(float64(s.Lat), float64(s.Lng)), id: len(idx.directory)})
+		idx.directory = append(idx.directory, s)
+
+		code := s.Settlement.Codes["yandex_code"]
+		if code == "" {
+			continue
+		}
+		c, ok := centroids[code]
+		if !ok {
+			c = &centroid{city: NearestCityResponse{
+				Code:  code,
+				Title: s.Settlement.Name,
+				Type:  "settlement",
+			}}
+			centroids[code] = c
+			order = append(order, code)
+		}
+		c.lat += float64(s.Lat)
+		c.lng += float64(s.Lng)
+		c.n++
+	}
+	idx.stations = newKDTree(points)
+
+	points = nil
+	for _, code := range order {
+		c := centroids[code]
+		c.city.Lat = c.lat / float64(c.n)
+		c.city.Lng = c.lng / float64(c.n)
+		points = append(points, kdPoint{pos: toCartesian(c.city.Lat, c.city.Lng), id: len(idx.cities)})
+		idx.cities = append(idx.cities, c.city)
+	}
+	idx.settlements = newKDTree(points)
+
+	return idx
+}
+
+// Nearest возвращает не более k ближайших станций в пределах radius километров (0 — без ограничения),
+// отсортированных по расстоянию
+func (i *SpatialIndex) Nearest(lat, lng float64, k int, radius float64, filter SpatialFilter) []NearestStation {
+	match := func(id int) bool {
+		return filter.match(&i.directory[id])
+	}
+	found := i.stations.nearest(toCartesian(lat, lng), k, chord(radius), match)
+
+	stations := make([]NearestStation, 0, len(found))
+	for _, f := range found {
+		stations = append(stations, i.nearestStation(f))
+	}
+	return stations
+}
+
+// Within возвращает все станции в пределах radius километров, отсортированные по расстоянию
+func (i *SpatialIndex) Within(lat, lng float64, radius float64, filter SpatialFilter) []NearestStation {
+	return i.Nearest(lat, lng, 0, radius, filter)
+}
+
+// NearestStations отвечает на запрос как Client.NearestStations. Расстояние по умолчанию не ограничено,
+// размер страницы по умолчанию 100, как в API.
+func (i *SpatialIndex) NearestStations(ctx context.Context, req NearestStationsRequest) (*NearestStationsResponse, error) {
+	if req.Lat == 0 || req.Lng == 0 {
+		return nil, validationError("unable to require params")
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, validationError("negative offset %d or limit %d", req.Offset, req.Limit)
+	}
+
+	var filter SpatialFilter
+	if req.StationType != "" {
+		filter.StationTypes = []string{req.StationType}
+	}
+	if req.TransportType != "" {
+		filter.TransportTypes = []TransportType{req.TransportType}
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	all := i.Nearest(req.Lat, req.Lng, 0, float64(req.Distance), filter)
+	resp := &NearestStationsResponse{
+		Pagination: Pagination{
+			Limit:  limit,
+			Offset: req.Offset,
+			Total:  len(all),
+		},
+	}
+	if req.Offset < len(all) {
+		all = all[req.Offset:]
+		if len(all) > limit {
+			all = all[:limit]
+		}
+		resp.Stations = all
+	}
+	return resp, nil
+}
+
+// NearestCity отвечает на запрос как Client.NearestCity. Если в пределах req.Distance нет населенных пунктов, возвращает ErrNotFound.
+func (i *SpatialIndex) NearestCity(ctx context.Context, req NearestCityRequest) (*NearestCityResponse, error) {
+	if req.Lat == 0 || req.Lng == 0 {
+		return nil, validationError("unable to require params")
+	}
+
+	found := i.settlements.nearest(toCartesian(req.Lat, req.Lng), 1, chord(float64(req.Distance)), nil)
+	if len(found) == 0 {
+		return nil, ErrNotFound
+	}
+	city := i.cities[found[0].id]
+	city.Distance = arc(found[0].dist)
+	return &city, nil
+}
+
+func (i *SpatialIndex) nearestStation(f kdResult) NearestStation {
+	s := i.directory[f.id]
+	return NearestStation{
+		Distance:      arc(f.dist),
+		Code:          s.Code,
+		StationType:   s.Type,
+		Title:         s.Title,
+		TransportType: TransportType(s.TransportType),
+		Lat:           float64(s.Lat),
+		Lng:           float64(s.Lng),
+		Type:          "station",
+	}
+}
+
+// toCartesian переводит координаты в точку на единичной сфере, чтобы сравнивать расстояния по хорде
+func toCartesian(lat, lng float64) [3]float64 {
+	phi := lat * math.Pi / 180
+	lambda := lng * math.Pi / 180
+	return [3]float64{
+		math.Cos(phi) * math.Cos(lambda),
+		math.Cos(phi) * math.Sin(lambda),
+		math.Sin(phi),
+	}
+}
+
+// chord переводит расстояние по поверхности в километрах в длину хорды единичной сферы, 0 — без ограничения
+func chord(km float64) float64 {
+	if km <= 0 {
+		return math.Inf(1)
+	}
+	return 2 * math.Sin(math.Min(km/earthRadius, math.Pi)/2)
+}
+
+// arc переводит длину хорды единичной сферы в расстояние по поверхности в километрах
+func arc(chord float64) float64 {
+	return 2 * earthRadius * math.Asin(math.Min(chord/2, 1))
+}
+
+type kdPoint struct {
+	pos [3]float64
+	id  int
+}
+
+type kdNode struct {
+	point       kdPoint
+	axis        int
+	left, right int // индексы в kdTree.nodes, -1 — нет потомка
+}
+
+type kdTree struct {
+	nodes []kdNode
+	root  int
+}
+
+func newKDTree(points []kdPoint) kdTree {
+	t := kdTree{nodes: make([]kdNode, 0, len(points))}
+	t.root = t.build(points, 0)
+	return t
+}
+
+func (t *kdTree) build(points []kdPoint, depth int) int {
+	if len(points) == 0 {
+		return -1
+	}
+	axis := depth % 3
+	sort.Slice(points, func(a, b int) bool {
+		return points[a].pos[axis] < points[b].pos[axis]
+	})
+	mid := len(points) / 2
+
+	idx := len(t.nodes)
+	t.nodes = append(t.nodes, kdNode{point: points[mid], axis: axis})
+	left := t.build(points[:mid], depth+1)
+	right := t.build(points[mid+1:], depth+1)
+	t.nodes[idx].left = left
+	t.nodes[idx].right = right
+	return idx
+}
+
+type kdResult struct {
+	id   int
+	dist float64 // длина хорды
+}
+
+// kdHeap куча результатов с наибольшим расстоянием на вершине
+type kdHeap []kdResult
+
+func (h kdHeap) Len() int            { return len(h) }
+func (h kdHeap) Less(i, j int) bool  { return h[i].dist > h[j].dist }
+func (h kdHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *kdHeap) Push(x interface{}) { *h = append(*h, x.(kdResult)) }
+func (h *kdHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// nearest возвращает не более k (0 — без ограничения) ближайших к pos точек в пределах хорды radius,
+// для которых match возвращает true (nil — все точки), по возрастанию расстояния
+func (t *kdTree) nearest(pos [3]float64, k int, radius float64, match func(id int) bool) []kdResult {
+	h := &kdHeap{}
+	var search func(n int)
+	search = func(n int) {
+		if n < 0 {
+			return
+		}
+		node := &t.nodes[n]
+
+		bound := radius
+		if k > 0 && h.Len() == k && (*h)[0].dist < bound {
+			bound = (*h)[0].dist
+		}
+
+		d := distance(pos, node.point.pos)
+		if d <= bound && (match == nil || match(node.point.id)) {
+			heap.Push(h, kdResult{id: node.point.id, dist: d})
+			if k > 0 && h.Len() > k {
+				heap.Pop(h)
+			}
+		}
+
+		diff := pos[node.axis] - node.point.pos[node.axis]
+		near, far := node.left, node.right
+		if diff > 0 {
+			near, far = far, near
+		}
+		search(near)
+
+		bound = radius
+		if k > 0 && h.Len() == k && (*h)[0].dist < bound {
+			bound = (*h)[0].dist
+		}
+		if math.Abs(diff) <= bound {
+			search(far)
+		}
+	}
+	search(t.root)
+
+	results := []kdResult(*h)
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].dist < results[b].dist
+	})
+	return results
+}
+
+func distance(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
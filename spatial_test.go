@@ -0,0 +1,122 @@
+package yandex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	p1, p2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dp, dl := p2-p1, (lng2-lng1)*math.Pi/180
+	a := math.Sin(dp/2)*math.Sin(dp/2) + math.Cos(p1)*math.Cos(p2)*math.Sin(dl/2)*math.Sin(dl/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// randomDirectory возвращает справочник из n станций вокруг Москвы в двух населенных пунктах
+func randomDirectory(n int) *StationDirectory {
+	r := rand.New(rand.NewSource(1))
+	settlements := []Settlement{
+		{Name: "Москва", Codes: Codes{"yandex_code": "c213"}},
+		{Name: "Химки", Codes: Codes{"yandex_code": "c10758"}},
+	}
+	for i := 0; i < n; i++ {
+		transport := Train
+		if i%3 == 0 {
+			transport = Bus
+		}
+		s := &settlements[i%2]
+		s.Stations = append(s.Stations, Station{
+			Codes:         Codes{"yandex_code": fmt.Sprintf("s%d", i)},
+			Title:         fmt.Sprintf("Станция %d", i),
+			Type:          "station",
+			TransportType: transport.String(),
+			Lat:           Coordinate(55.75 + float64(i%2)*0.14 + r.Float64()*0.2 - 0.1),
+			Lng:           Coordinate(37.6 - float64(i%2)*0.2 + r.Float64()*0.2 - 0.1),
+		})
+	}
+	settlements = append(settlements, Settlement{Name: "Без координат", Stations: []Station{{Codes: Codes{"yandex_code": "s-1"}}}})
+	return NewStationDirectory(&StationsListResponse{Countries: []Country{{
+		Name:    "Россия",
+		Regions: []Region{{Name: "Москва и Московская область", Settlements: settlements}},
+	}}})
+}
+
+func TestSpatialIndex_Nearest(t *testing.T) {
+	d := randomDirectory(500)
+	idx := NewSpatialIndex(d)
+	lat, lng := 55.76, 37.62
+
+	var want []NearestStation
+	for _, s := range d.Stations() {
+		if s.Lat != 0 && s.TransportType == "train" {
+			want = append(want, NearestStation{Code: s.Code, Distance: haversine(lat, lng, float64(s.Lat), float64(s.Lng))})
+		}
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i].Distance < want[j].Distance })
+
+	got := idx.Nearest(lat, lng, 10, 0, SpatialFilter{TransportTypes: []TransportType{Train}})
+	require.Len(t, got, 10)
+	for i := range got {
+		assert.Equal(t, want[i].Code, got[i].Code)
+		assert.InDelta(t, want[i].Distance, got[i].Distance, 1e-6)
+		assert.Equal(t, Train, got[i].TransportType)
+	}
+
+	var inRadius int
+	for _, s := range want {
+		if s.Distance <= 3 {
+			inRadius++
+		}
+	}
+	within := idx.Within(lat, lng, 3, SpatialFilter{TransportTypes: []TransportType{Train}})
+	assert.Len(t, within, inRadius)
+	for _, s := range within {
+		assert.True(t, s.Distance <= 3)
+	}
+}
+
+func TestSpatialIndex_NearestStations(t *testing.T) {
+	idx := NewSpatialIndex(randomDirectory(50))
+
+	resp, err := idx.NearestStations(context.TODO(), NearestStationsRequest{Lat: 55.76, Lng: 37.62, Distance: 100, TransportType: Bus, Offset: 5, Limit: 4})
+	require.NoError(t, err)
+	assert.Equal(t, Pagination{Limit: 4, Offset: 5, Total: 17}, resp.Pagination)
+	require.Len(t, resp.Stations, 4)
+	for _, s := range resp.Stations {
+		assert.Equal(t, Bus, s.TransportType)
+		assert.Equal(t, "station", s.Type)
+	}
+
+	_, err = idx.NearestStations(context.TODO(), NearestStationsRequest{})
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	for _, req := range []NearestStationsRequest{
+		{Lat: 55.76, Lng: 37.62, Offset: -1},
+		{Lat: 55.76, Lng: 37.62, Limit: -1},
+	} {
+		_, err = idx.NearestStations(context.TODO(), req)
+		assert.True(t, errors.Is(err, ErrValidation), "%+v", req)
+	}
+}
+
+func TestSpatialIndex_NearestCity(t *testing.T) {
+	idx := NewSpatialIndex(randomDirectory(50))
+
+	city, err := idx.NearestCity(context.TODO(), NearestCityRequest{Lat: 55.9, Lng: 37.4})
+	require.NoError(t, err)
+	assert.Equal(t, "c10758", city.Code)
+	assert.Equal(t, "Химки", city.Title)
+	assert.Equal(t, "settlement", city.Type)
+	assert.InDelta(t, haversine(55.9, 37.4, city.Lat, city.Lng), city.Distance, 1e-6)
+
+	_, err = idx.NearestCity(context.TODO(), NearestCityRequest{Lat: 59.93, Lng: 30.36, Distance: 50})
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
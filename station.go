@@ -1,14 +1,14 @@
 package yandex
 
 type Station struct {
-	Direction     string            `json:"direction"`
-	Codes         map[string]string `json:"codes"`
-	Type          string            `json:"station_type"`
-	Title         string            `json:"title"`
-	Lng           interface{}       `json:"longitude"`
-	Lat           interface{}       `json:"latitude"`
-	TransportType string            `json:"transport_type"`
-	Code 	      string 		`json:"code"`
+	Direction     string     `json:"direction" xml:"direction"`
+	Codes         Codes      `json:"codes" xml:"codes"`
+	Type          string     `json:"station_type" xml:"station_type"`
+	Title         string     `json:"title" xml:"title"`
+	Lng           Coordinate `json:"longitude" xml:"longitude"`
+	Lat           Coordinate `json:"latitude" xml:"latitude"`
+	TransportType string     `json:"transport_type" xml:"transport_type"`
+	Code          string     `json:"code" xml:"code"`
 
 	Region string
 	City   string
@@ -0,0 +1,390 @@
+package yandex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// StationsFunc вызывается для каждой станции списка. Country, Region и Settlement передаются
+// без вложенных списков. Чтобы остановить обход без ошибки, верните ErrStop.
+type StationsFunc func(country Country, region Region, settlement Settlement, station Station) error
+
+// StationsFilter отбор станций при обходе списка. Пустые поля не ограничивают обход.
+type StationsFilter struct {
+	Countries      []string        // Названия стран
+	TransportTypes []TransportType // Типы транспорта станций
+}
+
+func (f StationsFilter) country(name string) bool {
+	if len(f.Countries) == 0 {
+		return true
+	}
+	for _, c := range f.Countries {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f StationsFilter) station(s Station) bool {
+	if len(f.TransportTypes) == 0 {
+		return true
+	}
+	for _, t := range f.TransportTypes {
+		if t.String() == s.TransportType {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *client) StationsListStream(ctx context.Context, filter StationsFilter, fn StationsFunc) error {
+	u := url.URL{
+		Scheme: c.cfg.scheme(),
+		Host:   c.cfg.Host,
+		Path:   c.cfg.Version + "/stations_list/",
+	}
+
+	q := u.Query()
+	q.Set("format", c.cfg.Format.String())
+	q.Set("lang", c.cfg.Lang.String())
+
+	u.RawQuery = q.Encode()
+
+	return c.call(ctx, &Call{Endpoint: endpoint(u), Request: filter, URL: u}, func(ctx context.Context, call *Call) error {
+		return c.stream(ctx, call.URL, filter, fn)
+	})
+}
+
+// stream обходит список станций из кеша или из ответа API, не сохраняя ответ в кеш
+func (c *client) stream(ctx context.Context, u url.URL, filter StationsFilter, fn StationsFunc) error {
+	var body io.Reader
+	if c.cfg.Cache != nil && c.cfg.cacheTTL(endpoint(u)) > 0 && !cacheBypassed(ctx) {
+		if cached, ok := c.cfg.Cache.Get(u.String()); ok {
+			body = bytes.NewReader(cached)
+			if call := callFrom(ctx); call != nil {
+				call.Cached = true
+			}
+		}
+	}
+	if body == nil {
+		rc, err := c.retry(ctx, u)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		body = rc
+	}
+
+	var err error
+	if c.cfg.Format == JsonFormat {
+		err = newStationsWalker(body, filter, fn).walk()
+	} else {
+		// XML разбирается целиком
+		var resp StationsListResponse
+		if err = c.decodeReader(body, &resp); err == nil {
+			err = walkStations(&resp, filter, fn)
+		}
+	}
+	if errors.Is(err, ErrStop) {
+		return nil
+	}
+	return err
+}
+
+// walkStations обходит уже разобранный список станций
+func walkStations(resp *StationsListResponse, filter StationsFilter, fn StationsFunc) error {
+	for _, country := range resp.Countries {
+		if !filter.country(country.Name) {
+			continue
+		}
+		regions := country.Regions
+		country.Regions = nil
+		for _, region := range regions {
+			settlements := region.Settlements
+			region.Settlements = nil
+			for _, settlement := range settlements {
+				stations := settlement.Stations
+				settlement.Stations = nil
+				for _, station := range stations {
+					if !filter.station(station) {
+						continue
+					}
+					if err := fn(country, region, settlement, station); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// stationEntry станция вместе с заголовками родительских уровней
+type stationEntry struct {
+	country    Country
+	region     Region
+	settlement Settlement
+	station    Station
+}
+
+// maxPendingStations сколько станций walker откладывает в ожидании заголовков их уровней
+const maxPendingStations = 10000
+
+// stationsWalker разбирает JSON списка станций по токенам в ограниченной памяти.
+// Заголовок (title и codes) каждого уровня должен идти до списка вложенных объектов,
+// как в ответе API и в JSON структур Country, Region и Settlement: тогда в памяти держится одна станция.
+// Если список идет раньше заголовка, станции уровня откладываются до конца его объекта,
+// но не больше limit: дальше обход прерывается ошибкой.
+type stationsWalker struct {
+	dec    *json.Decoder
+	filter StationsFilter
+	fn     StationsFunc
+
+	limit   int // Предел отложенных станций
+	pending int // Отложено сейчас
+	peak    int // Наибольшее число отложенных станций
+}
+
+func newStationsWalker(r io.Reader, filter StationsFilter, fn StationsFunc) *stationsWalker {
+	return &stationsWalker{
+		dec:    json.NewDecoder(r),
+		filter: filter,
+		fn:     fn,
+		limit:  maxPendingStations,
+	}
+}
+
+// hold учитывает отложенную станцию
+func (w *stationsWalker) hold() error {
+	if w.pending >= w.limit {
+		return fmt.Errorf("stations_list: more than %d stations precede title and codes of their level", w.limit)
+	}
+	w.pending++
+	if w.pending > w.peak {
+		w.peak = w.pending
+	}
+	return nil
+}
+
+// flush передает отложенные станции в emit
+func (w *stationsWalker) flush(pending []stationEntry, emit func(stationEntry) error) error {
+	for _, e := range pending {
+		w.pending--
+		if err := emit(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *stationsWalker) walk() error {
+	return w.object(func(key string) error {
+		if key != "countries" {
+			return w.skip()
+		}
+		return w.array(w.country)
+	})
+}
+
+func (w *stationsWalker) country() error {
+	var country Country
+	var header int
+	var pending []stationEntry
+
+	emit := func(e stationEntry) error {
+		if !w.filter.country(country.Name) {
+			return nil
+		}
+		e.country = country
+		return w.fn(e.country, e.region, e.settlement, e.station)
+	}
+	collect := func(e stationEntry) error {
+		if header < 2 {
+			if err := w.hold(); err != nil {
+				return err
+			}
+			pending = append(pending, e)
+			return nil
+		}
+		return emit(e)
+	}
+
+	err := w.object(func(key string) error {
+		switch key {
+		case "title":
+			header++
+			return w.dec.Decode(&country.Name)
+		case "codes":
+			header++
+			return w.dec.Decode(&country.Codes)
+		case "regions":
+			if header == 2 && !w.filter.country(country.Name) {
+				return w.skip()
+			}
+			return w.array(func() error { return w.region(collect) })
+		}
+		return w.skip()
+	})
+	if err != nil {
+		return err
+	}
+	return w.flush(pending, emit)
+}
+
+func (w *stationsWalker) region(parent func(stationEntry) error) error {
+	var region Region
+	var header int
+	var pending []stationEntry
+
+	emit := func(e stationEntry) error {
+		e.region = region
+		return parent(e)
+	}
+	collect := func(e stationEntry) error {
+		if header < 2 {
+			if err := w.hold(); err != nil {
+				return err
+			}
+			pending = append(pending, e)
+			return nil
+		}
+		return emit(e)
+	}
+
+	err := w.object(func(key string) error {
+		switch key {
+		case "title":
+			header++
+			return w.dec.Decode(&region.Name)
+		case "codes":
+			header++
+			return w.dec.Decode(&region.Codes)
+		case "settlements":
+			return w.array(func() error { return w.settlement(collect) })
+		}
+		return w.skip()
+	})
+	if err != nil {
+		return err
+	}
+	return w.flush(pending, emit)
+}
+
+func (w *stationsWalker) settlement(parent func(stationEntry) error) error {
+	var settlement Settlement
+	var header int
+	var pending []stationEntry
+
+	emit := func(e stationEntry) error {
+		e.settlement = settlement
+		return parent(e)
+	}
+
+	err := w.object(func(key string) error {
+		switch key {
+		case "title":
+			header++
+			return w.dec.Decode(&settlement.Name)
+		case "codes":
+			header++
+			return w.dec.Decode(&settlement.Codes)
+		case "stations":
+			return w.array(func() error {
+				var station Station
+				if err := w.dec.Decode(&station); err != nil {
+					return err
+				}
+				if !w.filter.station(station) {
+					return nil
+				}
+				e := stationEntry{station: station}
+				if header < 2 {
+					if err := w.hold(); err != nil {
+						return err
+					}
+					pending = append(pending, e)
+					return nil
+				}
+				return emit(e)
+			})
+		}
+		return w.skip()
+	})
+	if err != nil {
+		return err
+	}
+	return w.flush(pending, emit)
+}
+
+// object читает объект, вызывая fn для каждого ключа. fn должна прочитать значение ключа.
+func (w *stationsWalker) object(fn func(key string) error) error {
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("stations_list: expected object, got %v", tok)
+	}
+	for w.dec.More() {
+		tok, err := w.dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(tok.(string)); err != nil {
+			return err
+		}
+	}
+	_, err = w.dec.Token()
+	return err
+}
+
+// array читает массив, вызывая fn для каждого элемента. fn должна прочитать элемент.
+func (w *stationsWalker) array(fn func() error) error {
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("stations_list: expected array, got %v", tok)
+	}
+	for w.dec.More() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	_, err = w.dec.Token()
+	return err
+}
+
+// skip пропускает значение, не сохраняя его в памяти
+func (w *stationsWalker) skip() error {
+	depth := 0
+	for {
+		tok, err := w.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
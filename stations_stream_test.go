@@ -0,0 +1,141 @@
+package yandex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamedStation struct {
+	Country    string
+	Region     string
+	Settlement string
+	Station    string
+}
+
+func collectStations(t *testing.T, c Client, filter StationsFilter) []streamedStation {
+	var got []streamedStation
+	err := c.StationsListStream(context.TODO(), filter, func(country Country, region Region, settlement Settlement, station Station) error {
+		assert.Nil(t, country.Regions)
+		assert.Nil(t, region.Settlements)
+		assert.Nil(t, settlement.Stations)
+		code, _ := station.ExternalID()
+		got = append(got, streamedStation{country.Name, region.Name, settlement.Name, code})
+		return nil
+	})
+	require.NoError(t, err)
+	return got
+}
+
+func TestClient_StationsListStream(t *testing.T) {
+	want := []streamedStation{
+		{"Россия", "Москва и Московская область", "Москва", "s2000001"},
+		{"Россия", "Москва и Московская область", "Москва", "s9876336"},
+	}
+
+	assert.Equal(t, want, collectStations(t, newFixtureClient(t, JsonFormat), StationsFilter{}))
+	assert.Equal(t, want, collectStations(t, newFixtureClient(t, XmlFormat), StationsFilter{}))
+}
+
+func TestClient_StationsListStreamFilter(t *testing.T) {
+	c := newFixtureClient(t, JsonFormat)
+
+	got := collectStations(t, c, StationsFilter{TransportTypes: []TransportType{Bus}})
+	require.Len(t, got, 1)
+	assert.Equal(t, "s9876336", got[0].Station)
+
+	assert.Empty(t, collectStations(t, c, StationsFilter{Countries: []string{"Беларусь"}}))
+}
+
+func TestClient_StationsListStreamHeaderAfterChildren(t *testing.T) {
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		return okResponse(`{"countries": [
+			{"regions": [
+				{"settlements": [
+					{"stations": [{"transport_type": "train", "codes": {"yandex_code": "s1"}}], "unknown": [1, {"a": [2]}], "codes": {}, "title": "Город"}
+				], "codes": {"yandex_code": "r1"}, "title": "Регион"}
+			], "codes": {"yandex_code": "l1"}, "title": "Страна"},
+			{"title": "Пропуск", "codes": {}, "regions": [{"title": "Регион 2", "codes": {}, "settlements": [{"title": "Город 2", "codes": {}, "stations": [{"codes": {"yandex_code": "s2"}}]}]}]}
+		]}`), nil
+	})
+
+	got := collectStations(t, c, StationsFilter{Countries: []string{"Страна"}})
+	assert.Equal(t, []streamedStation{{"Страна", "Регион", "Город", "s1"}}, got)
+}
+
+func TestStationsWalker_Pending(t *testing.T) {
+	station := `{"codes": {"yandex_code": "s%d"}}`
+	var stations []string
+	for i := 0; i < 5; i++ {
+		stations = append(stations, fmt.Sprintf(station, i))
+	}
+	listFirst := `{"countries": [{"regions": [{"settlements": [{"stations": [` + strings.Join(stations, ",") +
+		`], "title": "Город", "codes": {}}], "title": "Регион", "codes": {}}], "title": "Страна", "codes": {}}]}`
+	headerFirst := `{"countries": [{"title": "Страна", "codes": {}, "regions": [{"title": "Регион", "codes": {}, "settlements": [{"title": "Город", "codes": {}, "stations": [` +
+		strings.Join(stations, ",") + `]}]}]}]}`
+
+	var n int
+	count := func(Country, Region, Settlement, Station) error {
+		n++
+		return nil
+	}
+
+	w := newStationsWalker(strings.NewReader(headerFirst), StationsFilter{}, count)
+	require.NoError(t, w.walk())
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 0, w.peak)
+
+	n = 0
+	w = newStationsWalker(strings.NewReader(listFirst), StationsFilter{}, count)
+	require.NoError(t, w.walk())
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 5, w.peak)
+	assert.Equal(t, 0, w.pending)
+
+	n = 0
+	w = newStationsWalker(strings.NewReader(listFirst), StationsFilter{}, count)
+	w.limit = 3
+	assert.Error(t, w.walk())
+	assert.Equal(t, 3, w.peak)
+	assert.Equal(t, 0, n)
+}
+
+func TestClient_StationsListStreamStop(t *testing.T) {
+	c := newFixtureClient(t, JsonFormat)
+
+	var n int
+	err := c.StationsListStream(context.TODO(), StationsFilter{}, func(Country, Region, Settlement, Station) error {
+		n++
+		return ErrStop
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestClient_StationsListStreamWrappedStop(t *testing.T) {
+	c := newFixtureClient(t, JsonFormat)
+
+	var n int
+	err := c.StationsListStream(context.TODO(), StationsFilter{}, func(_ Country, _ Region, _ Settlement, s Station) error {
+		n++
+		return fmt.Errorf("station %s: %w", s.Title, ErrStop)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestClient_StationsListStreamInvalid(t *testing.T) {
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		return okResponse(strings.Repeat("[", 3)), nil
+	})
+
+	err := c.StationsListStream(context.TODO(), StationsFilter{}, func(Country, Region, Settlement, Station) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
@@ -1,10 +1,59 @@
 package yandex
 
+import (
+	"fmt"
+	"time"
+)
+
 type Stop struct {
-	Arrival   string  `json:"arrival"`
-	Departure string  `json:"departure"`
-	Terminal  string  `json:"terminal"`
-	Station   Station `json:"station"`
-	StopTime  int     `json:"stop_time"`
-	Duration  float64 `json:"duration"`
+	Arrival   string  `json:"arrival" xml:"arrival"`
+	Departure string  `json:"departure" xml:"departure"`
+	Terminal  string  `json:"terminal" xml:"terminal"`
+	Platform  string  `json:"platform" xml:"platform"`
+	Station   Station `json:"station" xml:"station"`
+	StopTime  int     `json:"stop_time" xml:"stop_time"`
+	Duration  float64 `json:"duration" xml:"duration"`
+}
+
+// ArrivalTime возвращает время прибытия на станцию. API отдает время нитки без смещения,
+// в местном времени станции, поэтому loc — часовой пояс станции, например Asia/Yekaterinburg.
+// Время со смещением сохраняет свой часовой пояс. Для начальной станции возвращается нулевое время.
+func (s *Stop) ArrivalTime(loc *time.Location) (time.Time, error) {
+	return ParseTime(s.Arrival, loc)
+}
+
+// DepartureTime возвращает время отправления со станции в часовом поясе станции loc, см. ArrivalTime.
+// Для конечной станции возвращается нулевое время.
+func (s *Stop) DepartureTime(loc *time.Location) (time.Time, error) {
+	return ParseTime(s.Departure, loc)
+}
+
+// TravelDuration возвращает время в пути от начальной станции
+func (s *Stop) TravelDuration() time.Duration {
+	return seconds(s.Duration)
+}
+
+// StopDuration возвращает время стоянки на станции
+func (s *Stop) StopDuration() time.Duration {
+	return time.Duration(s.StopTime) * time.Second
+}
+
+// StartDateTime возвращает время отправления с начальной станции нитки из StartDate и StartTime.
+// loc — часовой пояс начальной станции, см. Stop.DepartureTime. Если дата или время не заданы, возвращается нулевое время.
+func (r *ThreadResponse) StartDateTime(loc *time.Location) (time.Time, error) {
+	if r.StartDate == "" || r.StartTime == "" {
+		return time.Time{}, nil
+	}
+	if loc == nil {
+		return time.Time{}, fmt.Errorf("start time %s %s has no offset and station timezone is not set", r.StartDate, r.StartTime)
+	}
+
+	var err error
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, r.StartDate+" "+r.StartTime, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
 }
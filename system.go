@@ -0,0 +1,36 @@
+package yandex
+
+// system система кодирования станций и перевозчиков
+type system string
+
+func (s system) String() string {
+	return string(s)
+}
+
+const (
+	YandexSystem  system = "yandex"  // Коды Яндекс Расписаний
+	IataSystem    system = "iata"    // Коды IATA
+	SirenaSystem  system = "sirena"  // Коды системы «Сирена»
+	ExpressSystem system = "express" // Коды системы «Экспресс»
+	EsrSystem     system = "esr"     // Коды ЕСР
+
+	AllSystems system = "all" // Все системы кодирования, только для show_systems
+)
+
+// valid проверяет систему кодирования для параметра system
+func (s system) valid() bool {
+	switch s {
+	case YandexSystem, IataSystem, SirenaSystem, ExpressSystem, EsrSystem:
+		return true
+	}
+	return false
+}
+
+// validShow проверяет систему кодирования для параметра show_systems
+func (s system) validShow() bool {
+	switch s {
+	case YandexSystem, EsrSystem, AllSystems:
+		return true
+	}
+	return false
+}
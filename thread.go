@@ -1,13 +1,17 @@
 package yandex
 
 type Thread struct {
-	UID              string      `json:"uid"`
-	Title            string      `json:"title"`
-	Number           string      `json:"number"`
-	ShortTitle       string      `json:"short_title"`
-	ThreadMethodLink string      `json:"thread_method_link"`
-	Carrier          Carrier `json:"carrier"`
-	Address          string      `json:"address"`
-	Logo             string      `json:"logo"`
-	Email            string      `json:"email"`
+	UID              string        `json:"uid" xml:"uid"`
+	Title            string        `json:"title" xml:"title"`
+	Number           string        `json:"number" xml:"number"`
+	ShortTitle       string        `json:"short_title" xml:"short_title"`
+	ThreadMethodLink string        `json:"thread_method_link" xml:"thread_method_link"`
+	Carrier          Carrier       `json:"carrier" xml:"carrier"`
+	TransportType    TransportType `json:"transport_type" xml:"transport_type"`
+	Vehicle          string        `json:"vehicle" xml:"vehicle"`
+	ExpressType      string        `json:"express_type" xml:"express_type"`
+	Transport        *Transport    `json:"transport_subtype" xml:"transport_subtype"`
+	Address          string        `json:"address" xml:"address"`
+	Logo             string        `json:"logo" xml:"logo"`
+	Email            string        `json:"email" xml:"email"`
 }
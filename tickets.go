@@ -0,0 +1,64 @@
+package yandex
+
+import "fmt"
+
+// Информация о билетах
+type TicketsInfo struct {
+	EtMarker bool    `json:"et_marker" xml:"et_marker"` // Признак возможности электронной регистрации
+	Places   []Place `json:"places" xml:"places>place"` // Классы мест и цены на них
+}
+
+// Cheapest возвращает класс мест с минимальной ценой.
+// Валюта не учитывается: цены одного рейса указываются в одной валюте.
+func (t *TicketsInfo) Cheapest() (Place, bool) {
+	if t == nil || len(t.Places) == 0 {
+		return Place{}, false
+	}
+	cheapest := t.Places[0]
+	for _, p := range t.Places[1:] {
+		if p.Price.Minor() < cheapest.Price.Minor() {
+			cheapest = p
+		}
+	}
+	return cheapest, true
+}
+
+// Place возвращает класс мест по названию, например «Плацкартный»
+func (t *TicketsInfo) Place(name string) (Place, bool) {
+	if t == nil {
+		return Place{}, false
+	}
+	for _, p := range t.Places {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Place{}, false
+}
+
+// Класс мест
+type Place struct {
+	Name     string `json:"name" xml:"name"`         // Название класса мест
+	Currency string `json:"currency" xml:"currency"` // Валюта цены, например RUB
+	Price    Price  `json:"price" xml:"price"`       // Цена
+}
+
+// Total возвращает стоимость n билетов в минимальных единицах валюты
+func (p Place) Total(n int) int64 {
+	return p.Price.Minor() * int64(n)
+}
+
+// Цена билета
+type Price struct {
+	Whole int `json:"whole" xml:"whole"` // Целая часть
+	Cents int `json:"cents" xml:"cents"` // Дробная часть
+}
+
+// Minor возвращает цену в минимальных единицах валюты (копейках, центах)
+func (p Price) Minor() int64 {
+	return int64(p.Whole)*100 + int64(p.Cents)
+}
+
+func (p Price) String() string {
+	return fmt.Sprintf("%d.%02d", p.Whole, p.Cents)
+}
@@ -0,0 +1,45 @@
+package yandex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketsInfo(t *testing.T) {
+	var s Segment
+	require.NoError(t, json.Unmarshal([]byte(`{"tickets_info": {
+		"et_marker": true,
+		"places": [
+			{"name": "Купе", "currency": "RUB", "price": {"whole": 3500, "cents": 0}},
+			{"name": "Плацкартный", "currency": "RUB", "price": {"whole": 2113, "cents": 50}}
+		]
+	}}`), &s))
+
+	require.NotNil(t, s.TicketsInfo)
+	assert.True(t, s.TicketsInfo.EtMarker)
+
+	cheapest, ok := s.TicketsInfo.Cheapest()
+	require.True(t, ok)
+	assert.Equal(t, "Плацкартный", cheapest.Name)
+	assert.Equal(t, int64(211350), cheapest.Price.Minor())
+	assert.Equal(t, "2113.50", cheapest.Price.String())
+	assert.Equal(t, int64(422700), cheapest.Total(2))
+
+	place, ok := s.TicketsInfo.Place("Купе")
+	require.True(t, ok)
+	assert.Equal(t, "RUB", place.Currency)
+
+	_, ok = s.TicketsInfo.Place("СВ")
+	assert.False(t, ok)
+}
+
+func TestTicketsInfo_Nil(t *testing.T) {
+	var s Segment
+	require.NoError(t, json.Unmarshal([]byte(`{"tickets_info": null}`), &s))
+
+	_, ok := s.TicketsInfo.Cheapest()
+	assert.False(t, ok)
+}
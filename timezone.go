@@ -0,0 +1,56 @@
+package yandex
+
+import (
+	"fmt"
+	"time"
+)
+
+// Форматы времени в ответах API: со смещением (search, schedule) и без него (thread)
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// timezone возвращает название часового пояса для параметра result_timezone.
+// Принимаются только названия базы IANA, которые находит time.LoadLocation:
+// зоны time.FixedZone вроде «MSK» и time.Local API не поймет.
+func timezone(loc *time.Location) (string, error) {
+	name := loc.String()
+	if name == "" || name == "Local" {
+		return "", fmt.Errorf("timezone %q has no IANA name", name)
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", fmt.Errorf("timezone %q has no IANA name: %v", name, err)
+	}
+	return name, nil
+}
+
+// ParseTime разбирает время из ответа API. Время со смещением сохраняет часовой пояс станции,
+// время без смещения считается временем в loc. Если смещения нет и loc == nil, возвращается ошибка:
+// часовой пояс станции неизвестен. Для пустой строки возвращается нулевое время.
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if loc == nil {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("time %q has no offset and station timezone is not set", s)
+	}
+
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// seconds переводит длительность в секундах в time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
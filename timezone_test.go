@@ -0,0 +1,130 @@
+package yandex
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	tm, err := ParseTime("2020-01-10T08:00:00+05:00", nil)
+	require.NoError(t, err)
+	_, offset := tm.Zone()
+	assert.Equal(t, 5*60*60, offset)
+	assert.Equal(t, 8, tm.Hour())
+
+	_, err = ParseTime("2020-01-10 05:44:00", nil)
+	assert.Error(t, err)
+
+	tm, err = ParseTime("2020-01-10 05:44:00", time.UTC)
+	require.NoError(t, err)
+	assert.True(t, tm.Equal(time.Date(2020, 1, 10, 5, 44, 0, 0, time.UTC)))
+
+	tm, err = ParseTime("", nil)
+	require.NoError(t, err)
+	assert.True(t, tm.IsZero())
+
+	_, err = ParseTime("ежедневно", nil)
+	assert.Error(t, err)
+}
+
+func TestSegment_Times(t *testing.T) {
+	segments := []Segment{
+		{Departure: "2020-01-10T09:00:00+03:00", Arrival: "2020-01-10T14:00:00+05:00", Duration: 10800},
+		{Departure: "2020-01-10T07:30:00+05:00", Arrival: "2020-01-10T08:30:00+05:00", Duration: 3600.5},
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		a, _ := segments[i].DepartureTime()
+		b, _ := segments[j].DepartureTime()
+		return a.Before(b)
+	})
+	assert.Equal(t, "2020-01-10T07:30:00+05:00", segments[0].Departure)
+
+	arrival, err := segments[1].ArrivalTime()
+	require.NoError(t, err)
+	departure, err := segments[1].DepartureTime()
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Hour, arrival.Sub(departure))
+	assert.Equal(t, 3*time.Hour, segments[1].TravelDuration())
+	assert.Equal(t, time.Hour+500*time.Millisecond, segments[0].TravelDuration())
+
+	local := Segment{Departure: "2020-01-10 07:30:00", Arrival: "2020-01-10 08:30:00"}
+	_, err = local.DepartureTime()
+	assert.Error(t, err)
+	_, err = local.ArrivalTime()
+	assert.Error(t, err)
+}
+
+func TestSchedule_Times(t *testing.T) {
+	departure := "2020-01-10T08:35:00+03:00"
+	s := Schedule{Departure: &departure}
+
+	arrival, err := s.ArrivalTime()
+	require.NoError(t, err)
+	assert.True(t, arrival.IsZero())
+
+	tm, err := s.DepartureTime()
+	require.NoError(t, err)
+	assert.True(t, tm.Equal(time.Date(2020, 1, 10, 5, 35, 0, 0, time.UTC)))
+
+	local := "2020-01-10 08:35:00"
+	_, err = (&Schedule{Departure: &local}).DepartureTime()
+	assert.Error(t, err)
+	_, err = (&Schedule{Arrival: &local}).ArrivalTime()
+	assert.Error(t, err)
+}
+
+func TestStop_Durations(t *testing.T) {
+	s := Stop{Arrival: "2020-01-10 08:20:00", Duration: 9360, StopTime: 120}
+
+	arrival, err := s.ArrivalTime(moscow)
+	require.NoError(t, err)
+	assert.Equal(t, 8, arrival.Hour())
+	assert.Equal(t, 2*time.Hour+36*time.Minute, s.TravelDuration())
+	assert.Equal(t, 2*time.Minute, s.StopDuration())
+}
+
+func TestStop_StationTimezone(t *testing.T) {
+	vladivostok := time.FixedZone("VLAT", 10*60*60)
+	s := Stop{Arrival: "2020-01-10 08:20:00", Departure: "2020-01-10T08:40:00+10:00"}
+
+	arrival, err := s.ArrivalTime(vladivostok)
+	require.NoError(t, err)
+	assert.True(t, arrival.Equal(time.Date(2020, 1, 9, 22, 20, 0, 0, time.UTC)))
+
+	_, err = s.ArrivalTime(nil)
+	assert.Error(t, err)
+
+	departure, err := s.DepartureTime(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Minute, departure.Sub(arrival))
+
+	departure, err = (&Stop{}).DepartureTime(nil)
+	require.NoError(t, err)
+	assert.True(t, departure.IsZero())
+}
+
+func TestThreadResponse_StartDateTime(t *testing.T) {
+	yekaterinburg := time.FixedZone("YEKT", 5*60*60)
+	r := ThreadResponse{StartDate: "2020-01-10", StartTime: "05:44"}
+
+	start, err := r.StartDateTime(yekaterinburg)
+	require.NoError(t, err)
+	assert.True(t, start.Equal(time.Date(2020, 1, 10, 0, 44, 0, 0, time.UTC)))
+
+	r.StartTime = "05:44:30"
+	start, err = r.StartDateTime(yekaterinburg)
+	require.NoError(t, err)
+	assert.Equal(t, 30, start.Second())
+
+	_, err = r.StartDateTime(nil)
+	assert.Error(t, err)
+
+	start, err = (&ThreadResponse{}).StartDateTime(nil)
+	require.NoError(t, err)
+	assert.True(t, start.IsZero())
+}
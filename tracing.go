@@ -0,0 +1,171 @@
+package yandex
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+)
+
+// Tracer создает спаны вызовов API, например адаптер к OpenTelemetry
+type Tracer interface {
+	// Start начинает спан name дочерним к спану из ctx и возвращает контекст с новым спаном
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span спан одного вызова API
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	SpanContext() SpanContext
+	End()
+}
+
+// Attribute атрибут спана или события журнала
+type Attribute struct {
+	Key   string
+	Value interface{} // string, int, bool, float64 или time.Duration
+}
+
+// SpanContext идентификаторы спана для заголовка traceparent
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// Valid сообщает, заданы ли идентификаторы трассы и спана
+func (sc SpanContext) Valid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent возвращает значение заголовка W3C traceparent
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+// WithTracer создает спан «rasp.<метод API>» для каждого вызова, например «rasp.search»,
+// и передает его в заголовке traceparent каждого HTTP запроса
+func WithTracer(t Tracer) Option {
+	return func(o *options) error {
+		o.tracer = t
+		return nil
+	}
+}
+
+type spanKey struct{}
+
+// spanFrom возвращает спан вызова, выполняемого с контекстом ctx
+func spanFrom(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+func tracingMiddleware(t Tracer) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, call *Call) error {
+			ctx, span := t.Start(ctx, "rasp."+call.Endpoint)
+			defer span.End()
+			span.SetAttributes(requestAttributes(call.Request)...)
+
+			err := next.Do(context.WithValue(ctx, spanKey{}, span), call)
+
+			attrs := []Attribute{
+				{Key: "rasp.endpoint", Value: call.Endpoint},
+				{Key: "rasp.cached", Value: call.Cached},
+			}
+			if n := len(call.Attempts); n > 0 {
+				last := call.Attempts[n-1]
+				attrs = append(attrs,
+					Attribute{Key: "rasp.retries", Value: n - 1},
+					Attribute{Key: "rasp.key_index", Value: last.KeyIndex},
+				)
+				if last.Response != nil {
+					attrs = append(attrs, Attribute{Key: "http.status_code", Value: last.Response.StatusCode})
+				}
+			}
+			if err == nil {
+				if n, ok := resultCount(call.Result); ok {
+					attrs = append(attrs, Attribute{Key: "rasp.result_count", Value: n})
+				}
+			} else {
+				span.RecordError(err)
+			}
+			span.SetAttributes(attrs...)
+
+			return err
+		})
+	}
+}
+
+// requestAttributes возвращает атрибуты спана по параметрам запроса
+func requestAttributes(req interface{}) []Attribute {
+	var attrs []Attribute
+	add := func(key string, value interface{}) {
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				return
+			}
+		case int:
+			if v == 0 {
+				return
+			}
+		case time.Time:
+			if v.IsZero() {
+				return
+			}
+			value = v.Format(dateFormat)
+		}
+		attrs = append(attrs, Attribute{Key: key, Value: value})
+	}
+
+	switch r := req.(type) {
+	case SearchRequest:
+		add("rasp.from", r.From)
+		add("rasp.to", r.To)
+		add("rasp.date", r.Date)
+		add("rasp.offset", r.Offset)
+	case SchedulesRequest:
+		add("rasp.station", r.Station)
+		add("rasp.date", r.Time)
+		add("rasp.offset", r.Offset)
+	case ThreadRequest:
+		add("rasp.uid", r.UID)
+		add("rasp.from", r.From)
+		add("rasp.to", r.To)
+		add("rasp.date", r.Date)
+	case NearestStationsRequest:
+		add("rasp.lat", r.Lat)
+		add("rasp.lng", r.Lng)
+		add("rasp.offset", r.Offset)
+	case NearestCityRequest:
+		add("rasp.lat", r.Lat)
+		add("rasp.lng", r.Lng)
+	case CarrierRequest:
+		add("rasp.carrier", r.Code)
+	}
+	return attrs
+}
+
+// resultCount возвращает число элементов в ответе методов, возвращающих списки
+func resultCount(result interface{}) (int, bool) {
+	switch r := result.(type) {
+	case *SearchResponse:
+		return len(r.Segments) + len(r.IntervalSegments), true
+	case *SchedulesResponse:
+		return len(r.Schedule), true
+	case *ThreadResponse:
+		return len(r.Stops), true
+	case *NearestStationsResponse:
+		return len(r.Stations), true
+	case *StationsListResponse:
+		return len(r.Countries), true
+	case *CarrierResponse:
+		return len(r.Carriers), true
+	}
+	return 0, false
+}
@@ -0,0 +1,133 @@
+package yandex
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTracer запоминает завершенные спаны
+type memoryTracer struct {
+	mu    sync.Mutex
+	next  byte
+	spans []*memorySpan
+}
+
+type memorySpan struct {
+	tracer *memoryTracer
+	name   string
+	sc     SpanContext
+	attrs  map[string]interface{}
+	err    error
+}
+
+func (t *memoryTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next++
+	span := &memorySpan{tracer: t, name: name, attrs: make(map[string]interface{})}
+	span.sc.TraceID[15] = 1
+	span.sc.SpanID[7] = t.next
+	span.sc.Sampled = true
+	return ctx, span
+}
+
+func (s *memorySpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *memorySpan) RecordError(err error)    { s.err = err }
+func (s *memorySpan) SpanContext() SpanContext { return s.sc }
+
+func (s *memorySpan) End() {
+	s.tracer.mu.Lock()
+	s.tracer.spans = append(s.tracer.spans, s)
+	s.tracer.mu.Unlock()
+}
+
+func TestTracer(t *testing.T) {
+	var calls int
+	var parents []string
+	tracer := &memoryTracer{}
+	c, err := New(
+		WithKeys("first-key", "second-key"),
+		WithTracer(tracer),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			parents = append(parents, req.Header.Get("traceparent"))
+			if calls == 1 {
+				return statusResponse(http.StatusTooManyRequests), nil
+			}
+			return okResponse(`{"segments": [{}, {}], "interval_segments": [{}]}`), nil
+		})),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Search(context.TODO(), SearchRequest{
+		From:   "s2006004",
+		To:     "s9602494",
+		Date:   time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC),
+		Offset: 100,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, tracer.spans, 1)
+	span := tracer.spans[0]
+	assert.Equal(t, "rasp.search", span.name)
+	assert.NoError(t, span.err)
+	assert.Equal(t, map[string]interface{}{
+		"rasp.from":         "s2006004",
+		"rasp.to":           "s9602494",
+		"rasp.date":         "2020-01-10",
+		"rasp.offset":       100,
+		"rasp.endpoint":     "search",
+		"rasp.cached":       false,
+		"rasp.retries":      1,
+		"rasp.key_index":    1,
+		"http.status_code":  200,
+		"rasp.result_count": 3,
+	}, span.attrs)
+
+	traceparent := "00-00000000000000000000000000000001-0000000000000001-01"
+	assert.Equal(t, []string{traceparent, traceparent}, parents)
+}
+
+func TestTracer_Error(t *testing.T) {
+	tracer := &memoryTracer{}
+	c, err := New(
+		WithKeys("key"),
+		WithTracer(tracer),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return statusResponse(http.StatusNotFound), nil
+		})),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Thread(context.TODO(), ThreadRequest{UID: "unknown"})
+	require.Error(t, err)
+
+	require.Len(t, tracer.spans, 1)
+	span := tracer.spans[0]
+	assert.Equal(t, "rasp.thread", span.name)
+	assert.Equal(t, err, span.err)
+	assert.Equal(t, "unknown", span.attrs["rasp.uid"])
+	assert.Equal(t, 404, span.attrs["http.status_code"])
+	assert.NotContains(t, span.attrs, "rasp.result_count")
+}
+
+func TestSpanContext_TraceParent(t *testing.T) {
+	sc := SpanContext{
+		TraceID: [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	}
+	assert.True(t, sc.Valid())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", sc.TraceParent())
+	assert.False(t, SpanContext{}.Valid())
+}
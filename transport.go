@@ -2,7 +2,7 @@ package yandex
 
 type Transport struct {
 	// Основной цвет транспортного средства в шестнадцатеричном формате.
-	Color string `json:"color"`
+	Color string `json:"color" xml:"color"`
 	// Код подтипа транспорта для типа, указанного в элементе transport_type. Подтип может совпадать с типом (например, для обычной электрички указывается тип suburban и подтип suburban).
 	//
 	// Другие возможные значения:
@@ -28,7 +28,7 @@ type Transport struct {
 	// vag6 — состав из 6 вагонов (для типа suburban);
 	// river— речной транспорт (для типа water);
 	// sea — морской транспорт (для типа water).
-	Code string `json:"code"`
+	Code string `json:"code" xml:"code"`
 	// Описание подтипа транспорта на естественном языке.
-	Title string `json:"title"`
+	Title string `json:"title" xml:"title"`
 }
@@ -14,3 +14,11 @@ const (
 func (t TransportType) String() string {
 	return string(t)
 }
+
+func (t TransportType) valid() bool {
+	switch t {
+	case Plane, Train, Suburban, Bus, Water, Helicopter:
+		return true
+	}
+	return false
+}
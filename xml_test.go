@@ -0,0 +1,91 @@
+package yandex
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFixtureClient возвращает клиент, который отвечает файлами testdata/<метод>.<формат>
+func newFixtureClient(t *testing.T, f format) *client {
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		name := filepath.Join("testdata", endpoint(*req.URL)+"."+req.URL.Query().Get("format"))
+		body, err := ioutil.ReadFile(name)
+		require.NoError(t, err)
+		return okResponse(string(body)), nil
+	})
+	c.cfg.Format = f
+	return c
+}
+
+func TestXMLFormat(t *testing.T) {
+	date := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	calls := map[string]func(c *client) (interface{}, error){
+		"search": func(c *client) (interface{}, error) {
+			return c.Search(context.TODO(), SearchRequest{From: "c213", To: "c2", Date: date})
+		},
+		"schedule": func(c *client) (interface{}, error) {
+			return c.Schedules(context.TODO(), SchedulesRequest{Station: "s9600213", Time: date})
+		},
+		"thread": func(c *client) (interface{}, error) {
+			return c.Thread(context.TODO(), ThreadRequest{UID: "6001_0_9600212_g20_4"})
+		},
+		"stations_list": func(c *client) (interface{}, error) {
+			return c.StationsList(context.TODO())
+		},
+		"nearest_stations": func(c *client) (interface{}, error) {
+			return c.NearestStations(context.TODO(), NearestStationsRequest{Lat: 59.93, Lng: 30.36})
+		},
+		"nearest_settlement": func(c *client) (interface{}, error) {
+			return c.NearestCity(context.TODO(), NearestCityRequest{Lat: 59.93, Lng: 30.36})
+		},
+		"carrier": func(c *client) (interface{}, error) {
+			return c.Carrier(context.TODO(), CarrierRequest{Code: "TUR", System: IataSystem})
+		},
+		"copyright": func(c *client) (interface{}, error) {
+			return c.Copyright(context.TODO())
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			fromJSON, err := call(newFixtureClient(t, JsonFormat))
+			require.NoError(t, err)
+			fromXML, err := call(newFixtureClient(t, XmlFormat))
+			require.NoError(t, err)
+
+			assert.Equal(t, fromJSON, fromXML)
+		})
+	}
+}
+
+func TestXMLFormat_Values(t *testing.T) {
+	c := newFixtureClient(t, XmlFormat)
+
+	search, err := c.Search(context.TODO(), SearchRequest{From: "c213", To: "c2"})
+	require.NoError(t, err)
+	require.Len(t, search.Segments, 1)
+	assert.Equal(t, "752A_0_2", search.Segments[0].Thread.UID)
+	assert.Equal(t, "sapsan", search.Segments[0].Thread.Transport.Code)
+	cheapest, ok := search.Segments[0].TicketsInfo.Cheapest()
+	require.True(t, ok)
+	assert.Equal(t, "Эконом", cheapest.Name)
+	require.Len(t, search.IntervalSegments, 1)
+	assert.Equal(t, "раз в 30 минут", search.IntervalSegments[0].Interval.Density)
+
+	list, err := c.StationsList(context.TODO())
+	require.NoError(t, err)
+	station := list.Countries[0].Regions[0].Settlements[0].Stations[0]
+	assert.Equal(t, "191602", station.Codes["esr_code"])
+	assert.Equal(t, Coordinate(55.757139), station.Lat)
+
+	nearest, err := c.NearestStations(context.TODO(), NearestStationsRequest{Lat: 59.93, Lng: 30.36})
+	require.NoError(t, err)
+	assert.Equal(t, "https://rasp.yandex.ru/station/9600366/", nearest.Stations[0].TypeChoices["schedule"].DesktopURL)
+}
@@ -0,0 +1,171 @@
+package yarasptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Mode режим работы Recorder
+type Mode int
+
+const (
+	Replay Mode = iota // Ответы берутся из кассеты, запросы без записи завершаются ошибкой
+	Record             // Запросы уходят в API, ответы записываются в кассету
+)
+
+// Interaction записанный запрос и ответ на него. API ключ в кассету не попадает.
+type Interaction struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// Recorder http.RoundTripper, который записывает ответы API в файл кассеты и воспроизводит их.
+// В режиме Record кассету нужно сохранить методом Save.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder создает Recorder для кассеты path. В режиме Replay кассета читается из файла,
+// в режиме Record запросы выполняются через transport (nil — http.DefaultTransport).
+func NewRecorder(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+	}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+
+	if mode == Replay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return nil, fmt.Errorf("yarasptest: cassette %s: %v", path, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == Record {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+// Save записывает кассету в файл. В режиме Replay ничего не делает.
+func (r *Recorder) Save() error {
+	if r.mode != Record {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, data, 0644)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	header := make(http.Header)
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		header.Set("Content-Type", ct)
+	}
+
+	scrubbed := string(body)
+	for _, key := range requestKeys(req) {
+		scrubbed = strings.Replace(scrubbed, key, "****", -1)
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Method: req.Method,
+		URL:    matchURL(req.URL),
+		Status: resp.StatusCode,
+		Header: header,
+		Body:   scrubbed,
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay отдает первую неиспользованную запись с тем же методом и URL
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	u := matchURL(req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.interactions {
+		if r.used[i] || in.Method != req.Method || in.URL != u {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+			StatusCode:    in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Header,
+			Body:          ioutil.NopCloser(strings.NewReader(in.Body)),
+			ContentLength: int64(len(in.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("yarasptest: no recorded interaction for %s %s in %s", req.Method, u, r.path)
+}
+
+// matchURL возвращает URL запроса без API ключа с отсортированными параметрами
+func matchURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	q.Del("apikey")
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+// requestKeys возвращает API ключи, переданные в запросе
+func requestKeys(req *http.Request) []string {
+	var keys []string
+	if key := req.Header.Get("Authorization"); key != "" {
+		keys = append(keys, key)
+	}
+	if key := req.URL.Query().Get("apikey"); key != "" {
+		keys = append(keys, key)
+	}
+	return keys
+}
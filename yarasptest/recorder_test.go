@@ -0,0 +1,61 @@
+package yarasptest_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	yandex "github.com/Yurovskikh/ya-rasp"
+	"github.com/Yurovskikh/ya-rasp/yarasptest"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yarasptest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cassette := filepath.Join(dir, "cassette.json")
+	const key = "0b1c2d3e-4f50-6172-8394-a5b6c7d8e9f0"
+
+	srv := yarasptest.NewServer(dataset())
+	opts := srv.Options(key)
+
+	rec, err := yarasptest.NewRecorder(cassette, yarasptest.Record, nil)
+	require.NoError(t, err)
+	c, err := yandex.New(append(opts, yandex.WithTransport(rec))...)
+	require.NoError(t, err)
+
+	recorded, err := c.Thread(context.TODO(), yandex.ThreadRequest{UID: "752A_0_2"})
+	require.NoError(t, err)
+	_, err = c.Thread(context.TODO(), yandex.ThreadRequest{UID: "unknown"})
+	require.Error(t, err)
+	require.NoError(t, rec.Save())
+	srv.Close()
+
+	data, err := ioutil.ReadFile(cassette)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), key)
+
+	rec, err = yarasptest.NewRecorder(cassette, yarasptest.Replay, nil)
+	require.NoError(t, err)
+	c, err = yandex.New(append(opts, yandex.WithTransport(rec), yandex.WithRetry(yandex.RetryPolicy{MaxAttempts: 1}))...)
+	require.NoError(t, err)
+
+	replayed, err := c.Thread(context.TODO(), yandex.ThreadRequest{UID: "752A_0_2"})
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+
+	_, err = c.Thread(context.TODO(), yandex.ThreadRequest{UID: "unknown"})
+	var apiErr *yandex.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 404, apiErr.StatusCode)
+
+	_, err = c.Copyright(context.TODO())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded interaction")
+}
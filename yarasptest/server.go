@@ -0,0 +1,409 @@
+// Package yarasptest предоставляет тестовый сервер API Яндекс Расписаний на основе httptest.
+//
+// Сервер отвечает на все методы клиента по данным из Dataset, поддерживает постраничный вывод,
+// форматы JSON и XML, ошибки по запросу и исчерпание квоты ключей (ответ 429).
+package yarasptest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	yandex "github.com/Yurovskikh/ya-rasp"
+)
+
+// Dataset данные, которыми отвечает сервер
+type Dataset struct {
+	StationsList yandex.StationsListResponse      // stations_list, по нему же отвечают nearest_stations и nearest_settlement
+	Segments     []yandex.Segment                 // search, отбор по From.Code и To.Code
+	Schedules    map[string][]yandex.Schedule     // schedule по коду станции
+	Threads      map[string]yandex.ThreadResponse // thread по uid
+	Carriers     []yandex.Carrier                 // carrier
+	Copyright    yandex.Copyright                 // copyright
+}
+
+// Server тестовый сервер API
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	data     Dataset
+	spatial  *yandex.SpatialIndex
+	quotas   map[string]int
+	failures map[string][]int
+	requests map[string]int
+}
+
+// NewServer запускает сервер с данными data. Сервер нужно остановить методом Close.
+func NewServer(data Dataset) *Server {
+	s := &Server{
+		data:     data,
+		spatial:  yandex.NewSpatialIndex(yandex.NewStationDirectory(&data.StationsList)),
+		failures: make(map[string][]int),
+		requests: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Options возвращает опции клиента для работы с сервером и ключами keys.
+// Опции, переданные в yandex.New после них, дополняют или переопределяют их.
+func (s *Server) Options(keys ...string) []yandex.Option {
+	return []yandex.Option{
+		yandex.WithScheme("http"),
+		yandex.WithHost(s.Listener.Addr().String()),
+		yandex.WithKeys(keys...),
+	}
+}
+
+// SetQuota ограничивает число запросов с ключом key. После исчерпания квоты сервер отвечает 429.
+// Если квота не задана ни для одного ключа, сервер принимает любой непустой ключ,
+// иначе ключи без квоты считаются неверными и получают 403.
+func (s *Server) SetQuota(key string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.quotas == nil {
+		s.quotas = make(map[string]int)
+	}
+	s.quotas[key] = n
+}
+
+// FailNext задает коды ответов для следующих запросов к методу endpoint, например «search».
+// Каждый код используется один раз.
+func (s *Server) FailNext(endpoint string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[endpoint] = append(s.failures[endpoint], statuses...)
+}
+
+// Requests возвращает число запросов к методу endpoint, включая неуспешные
+func (s *Server) Requests(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[endpoint]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	endpoint := path.Base(r.URL.Path)
+	q := r.URL.Query()
+	format := q.Get("format")
+
+	if status, ok := s.check(endpoint, r); !ok {
+		writeError(w, format, status, r)
+		return
+	}
+
+	var resp interface{}
+	status := http.StatusOK
+	switch endpoint {
+	case "search":
+		resp, status = s.search(q)
+	case "schedule":
+		resp, status = s.schedule(q)
+	case "thread":
+		resp, status = s.thread(q)
+	case "stations_list":
+		resp = &s.data.StationsList
+	case "nearest_stations":
+		resp, status = s.nearestStations(r)
+	case "nearest_settlement":
+		resp, status = s.nearestSettlement(r)
+	case "carrier":
+		resp, status = s.carrier(q)
+	case "copyright":
+		resp = &yandex.CopyrightResponse{Copyright: s.data.Copyright}
+	default:
+		status = http.StatusNotFound
+	}
+	if status != http.StatusOK {
+		writeError(w, format, status, r)
+		return
+	}
+
+	write(w, format, http.StatusOK, resp)
+}
+
+// check учитывает запрос, проверяет ключ, квоту и заданные ошибки
+func (s *Server) check(endpoint string, r *http.Request) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[endpoint]++
+
+	key := r.Header.Get("Authorization")
+	if key == "" {
+		key = r.URL.Query().Get("apikey")
+	}
+	if key == "" {
+		return http.StatusUnauthorized, false
+	}
+	if s.quotas != nil {
+		n, ok := s.quotas[key]
+		if !ok {
+			return http.StatusForbidden, false
+		}
+		if n <= 0 {
+			return http.StatusTooManyRequests, false
+		}
+		s.quotas[key] = n - 1
+	}
+
+	if failures := s.failures[endpoint]; len(failures) > 0 {
+		s.failures[endpoint] = failures[1:]
+		return failures[0], false
+	}
+	return http.StatusOK, true
+}
+
+func (s *Server) search(q map[string][]string) (interface{}, int) {
+	from, to := get(q, "from"), get(q, "to")
+	if from == "" || to == "" {
+		return nil, http.StatusBadRequest
+	}
+	date := get(q, "date")
+	transport := get(q, "transport_types")
+
+	var segments []yandex.Segment
+	for _, seg := range s.data.Segments {
+		if seg.From.Code != from || seg.To.Code != to {
+			continue
+		}
+		if date != "" && !strings.HasPrefix(seg.Departure, date) {
+			continue
+		}
+		if transport != "" && seg.Thread.TransportType.String() != transport {
+			continue
+		}
+		if seg.HasTransfers && get(q, "transfers") != "true" {
+			continue
+		}
+		segments = append(segments, seg)
+	}
+
+	resp := &yandex.SearchResponse{
+		Search: yandex.SearchInfo{
+			Date: date,
+			From: yandex.Point{Code: from},
+			To:   yandex.Point{Code: to},
+		},
+	}
+	start, end, p, ok := paginate(q, len(segments))
+	if !ok {
+		return nil, http.StatusBadRequest
+	}
+	resp.Pagination = p
+	resp.Segments = segments[start:end]
+	return resp, http.StatusOK
+}
+
+func (s *Server) schedule(q map[string][]string) (interface{}, int) {
+	station := get(q, "station")
+	if station == "" {
+		return nil, http.StatusBadRequest
+	}
+	schedule, ok := s.data.Schedules[station]
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+
+	resp := &yandex.SchedulesResponse{
+		Date:    get(q, "date"),
+		Station: yandex.Station{Code: station},
+	}
+	start, end, p, ok := paginate(q, len(schedule))
+	if !ok {
+		return nil, http.StatusBadRequest
+	}
+	resp.Pagination = p
+	resp.Schedule = schedule[start:end]
+	return resp, http.StatusOK
+}
+
+func (s *Server) thread(q map[string][]string) (interface{}, int) {
+	uid := get(q, "uid")
+	if uid == "" {
+		return nil, http.StatusBadRequest
+	}
+	thread, ok := s.data.Threads[uid]
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+	return &thread, http.StatusOK
+}
+
+func (s *Server) nearestStations(r *http.Request) (interface{}, int) {
+	lat, lng, ok := coordinates(r)
+	if !ok {
+		return nil, http.StatusBadRequest
+	}
+	q := r.URL.Query()
+	distance, _ := strconv.Atoi(get(q, "distance"))
+	offset, limit, ok := pageParams(q)
+	if !ok {
+		return nil, http.StatusBadRequest
+	}
+
+	resp, err := s.spatial.NearestStations(r.Context(), yandex.NearestStationsRequest{
+		Lat:           lat,
+		Lng:           lng,
+		StationType:   get(q, "station_types"),
+		TransportType: yandex.TransportType(get(q, "transport_types")),
+		Distance:      distance,
+		Offset:        offset,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, http.StatusBadRequest
+	}
+	return resp, http.StatusOK
+}
+
+func (s *Server) nearestSettlement(r *http.Request) (interface{}, int) {
+	lat, lng, ok := coordinates(r)
+	if !ok {
+		return nil, http.StatusBadRequest
+	}
+	distance, _ := strconv.Atoi(r.URL.Query().Get("distance"))
+
+	resp, err := s.spatial.NearestCity(r.Context(), yandex.NearestCityRequest{
+		Lat:      lat,
+		Lng:      lng,
+		Distance: distance,
+	})
+	if err != nil {
+		return nil, http.StatusNotFound
+	}
+	return resp, http.StatusOK
+}
+
+func (s *Server) carrier(q map[string][]string) (interface{}, int) {
+	code := get(q, "code")
+	if code == "" {
+		return nil, http.StatusBadRequest
+	}
+	system := get(q, "system")
+
+	var carriers []yandex.Carrier
+	for _, c := range s.data.Carriers {
+		var match bool
+		switch system {
+		case "", yandex.YandexSystem.String():
+			match = strconv.Itoa(c.Code) == code
+		case yandex.IataSystem.String():
+			match = c.Codes.Iata == code
+		case yandex.SirenaSystem.String():
+			match = c.Codes.Sirena == code
+		}
+		if match {
+			carriers = append(carriers, c)
+		}
+	}
+	if len(carriers) == 0 {
+		return nil, http.StatusNotFound
+	}
+
+	resp := &yandex.CarrierResponse{Carrier: carriers[0]}
+	if len(carriers) > 1 {
+		resp.Carriers = carriers
+	}
+	return resp, http.StatusOK
+}
+
+// pageParams разбирает параметры offset и limit. Отрицательные и нечисловые значения, как и API, не принимаются.
+func pageParams(q map[string][]string) (int, int, bool) {
+	var values [2]int
+	for i, key := range []string{"offset", "limit"} {
+		v := get(q, key)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, false
+		}
+		values[i] = n
+	}
+	return values[0], values[1], true
+}
+
+// paginate возвращает границы страницы по параметрам offset и limit, по умолчанию limit 100
+func paginate(q map[string][]string, total int) (int, int, yandex.Pagination, bool) {
+	offset, limit, ok := pageParams(q)
+	if !ok {
+		return 0, 0, yandex.Pagination{}, false
+	}
+	if limit == 0 {
+		limit = 100
+	}
+	p := yandex.Pagination{Total: total, Limit: limit, Offset: offset}
+
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return offset, end, p, true
+}
+
+func coordinates(r *http.Request) (float64, float64, bool) {
+	lat, err := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+func get(q map[string][]string, key string) string {
+	if v := q[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+type apiError struct {
+	Text     string `json:"text" xml:"text"`
+	HTTPCode int    `json:"http_code" xml:"http_code"`
+	Code     string `json:"error_code" xml:"error_code"`
+	Request  string `json:"request" xml:"request"`
+}
+
+func writeError(w http.ResponseWriter, format string, status int, r *http.Request) {
+	write(w, format, status, &struct {
+		Error apiError `json:"error" xml:"error"`
+	}{
+		Error: apiError{
+			Text:     http.StatusText(status),
+			HTTPCode: status,
+			Code:     strings.ToLower(strings.Replace(http.StatusText(status), " ", "_", -1)),
+			Request:  "http://" + r.Host + r.URL.RequestURI(),
+		},
+	})
+}
+
+func write(w http.ResponseWriter, format string, status int, v interface{}) {
+	if format == yandex.XmlFormat.String() {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(status)
+		w.Write([]byte(xml.Header))
+		xml.NewEncoder(w).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: "response"}})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,181 @@
+package yarasptest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	yandex "github.com/Yurovskikh/ya-rasp"
+	"github.com/Yurovskikh/ya-rasp/yarasptest"
+)
+
+func dataset() yarasptest.Dataset {
+	moscow := yandex.Station{Code: "s2006004", Title: "Москва (Ленинградский вокзал)"}
+	piter := yandex.Station{Code: "s9602494", Title: "Санкт-Петербург (Московский вокзал)"}
+
+	var schedule []yandex.Schedule
+	for i := 0; i < 250; i++ {
+		schedule = append(schedule, yandex.Schedule{Thread: yandex.Thread{UID: fmt.Sprint(i)}})
+	}
+
+	return yarasptest.Dataset{
+		StationsList: yandex.StationsListResponse{Countries: []yandex.Country{{
+			Name: "Россия",
+			Regions: []yandex.Region{{
+				Name: "Санкт-Петербург и Ленинградская область",
+				Settlements: []yandex.Settlement{{
+					Name:  "Санкт-Петербург",
+					Codes: yandex.Codes{"yandex_code": "c2"},
+					Stations: []yandex.Station{{
+						Codes:         yandex.Codes{"yandex_code": "s9602494", "esr_code": "031812"},
+						Title:         "Санкт-Петербург (Московский вокзал)",
+						Type:          "train_station",
+						TransportType: "train",
+						Lat:           59.929925,
+						Lng:           30.362215,
+					}},
+				}},
+			}},
+		}}},
+		Segments: []yandex.Segment{
+			{From: moscow, To: piter, Departure: "2020-01-10T08:00:00+03:00", Thread: yandex.Thread{UID: "752A_0_2", TransportType: yandex.Train}},
+			{From: moscow, To: piter, Departure: "2020-01-11T08:00:00+03:00", Thread: yandex.Thread{UID: "752A_0_2", TransportType: yandex.Train}},
+			{From: moscow, To: piter, Departure: "2020-01-10T10:00:00+03:00", HasTransfers: true},
+		},
+		Schedules: map[string][]yandex.Schedule{"s2006004": schedule},
+		Threads: map[string]yandex.ThreadResponse{
+			"752A_0_2": {UID: "752A_0_2", Number: "752А", Stops: []yandex.Stop{{Station: moscow}, {Station: piter}}},
+		},
+		Carriers: []yandex.Carrier{
+			{Code: 112, Title: "РЖД/ФПК", Codes: yandex.CarrierCodes{Sirena: "ФПК"}},
+		},
+		Copyright: yandex.Copyright{Text: "Данные предоставлены сервисом Яндекс.Расписания", URL: "http://rasp.yandex.ru/"},
+	}
+}
+
+func TestServer_Endpoints(t *testing.T) {
+	srv := yarasptest.NewServer(dataset())
+	defer srv.Close()
+
+	for _, format := range []string{"json", "xml"} {
+		t.Run(format, func(t *testing.T) {
+			opts := srv.Options("key")
+			if format == "xml" {
+				opts = append(opts, yandex.WithFormat(yandex.XmlFormat))
+			}
+			c, err := yandex.New(opts...)
+			require.NoError(t, err)
+			ctx := context.TODO()
+
+			search, err := c.Search(ctx, yandex.SearchRequest{From: "s2006004", To: "s9602494", Date: time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)})
+			require.NoError(t, err)
+			require.Len(t, search.Segments, 1)
+			assert.Equal(t, "752A_0_2", search.Segments[0].Thread.UID)
+
+			search, err = c.Search(ctx, yandex.SearchRequest{From: "s2006004", To: "s9602494", Transfers: true})
+			require.NoError(t, err)
+			assert.Len(t, search.Segments, 3)
+
+			schedules, err := c.Schedules(ctx, yandex.SchedulesRequest{Station: "s2006004"})
+			require.NoError(t, err)
+			assert.Equal(t, 250, schedules.Pagination.Total)
+			assert.Len(t, schedules.Schedule, 100)
+
+			thread, err := c.Thread(ctx, yandex.ThreadRequest{UID: "752A_0_2"})
+			require.NoError(t, err)
+			assert.Equal(t, "752А", thread.Number)
+			assert.Len(t, thread.Stops, 2)
+
+			list, err := c.StationsList(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "031812", list.Countries[0].Regions[0].Settlements[0].Stations[0].Codes["esr_code"])
+
+			nearest, err := c.NearestStations(ctx, yandex.NearestStationsRequest{Lat: 59.93, Lng: 30.36, Distance: 10})
+			require.NoError(t, err)
+			require.Len(t, nearest.Stations, 1)
+			assert.Equal(t, "s9602494", nearest.Stations[0].Code)
+
+			city, err := c.NearestCity(ctx, yandex.NearestCityRequest{Lat: 59.93, Lng: 30.36})
+			require.NoError(t, err)
+			assert.Equal(t, "c2", city.Code)
+
+			carrier, err := c.Carrier(ctx, yandex.CarrierRequest{Code: "ФПК", System: yandex.SirenaSystem})
+			require.NoError(t, err)
+			assert.Equal(t, 112, carrier.Carrier.Code)
+
+			copyright, err := c.Copyright(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "http://rasp.yandex.ru/", copyright.Copyright.URL)
+		})
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	srv := yarasptest.NewServer(dataset())
+	defer srv.Close()
+	c, err := yandex.New(srv.Options("key")...)
+	require.NoError(t, err)
+
+	schedules, err := yandex.SchedulesAll(context.TODO(), c, yandex.SchedulesRequest{Station: "s2006004", Limit: 40}, 0)
+	require.NoError(t, err)
+	require.Len(t, schedules, 250)
+	assert.Equal(t, "249", schedules[249].Thread.UID)
+	assert.Equal(t, 7, srv.Requests("schedule"))
+}
+
+func TestServer_Errors(t *testing.T) {
+	srv := yarasptest.NewServer(dataset())
+	defer srv.Close()
+	c, err := yandex.New(srv.Options("key")...)
+	require.NoError(t, err)
+
+	_, err = c.Thread(context.TODO(), yandex.ThreadRequest{UID: "unknown"})
+	assert.True(t, errors.Is(err, yandex.ErrNotFound))
+
+	srv.FailNext("copyright", http.StatusBadGateway, http.StatusInternalServerError)
+	_, err = c.Copyright(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 3, srv.Requests("copyright"))
+
+	srv.FailNext("copyright", http.StatusBadRequest)
+	_, err = c.Copyright(context.TODO())
+	var apiErr *yandex.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "copyright", apiErr.Endpoint)
+	assert.Equal(t, "bad_request", apiErr.Code)
+
+	_, err = c.Schedules(context.TODO(), yandex.SchedulesRequest{Station: "s2006004", Offset: -1})
+	assert.True(t, errors.Is(err, yandex.ErrValidation))
+	_, err = c.Search(context.TODO(), yandex.SearchRequest{From: "s2006004", To: "s9602494", Limit: -5})
+	assert.True(t, errors.Is(err, yandex.ErrValidation))
+	_, err = c.NearestStations(context.TODO(), yandex.NearestStationsRequest{Lat: 59.93, Lng: 30.36, Offset: -1})
+	assert.True(t, errors.Is(err, yandex.ErrValidation))
+}
+
+func TestServer_KeyExhaustion(t *testing.T) {
+	srv := yarasptest.NewServer(dataset())
+	defer srv.Close()
+	srv.SetQuota("first", 1)
+	srv.SetQuota("second", 2)
+	c, err := yandex.New(srv.Options("first", "second")...)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.Copyright(context.TODO())
+		require.NoError(t, err, "request %d", i)
+	}
+
+	_, err = c.Copyright(context.TODO())
+	assert.True(t, errors.Is(err, yandex.ErrQuotaExceeded))
+
+	c, err = yandex.New(srv.Options("unknown")...)
+	require.NoError(t, err)
+	_, err = c.Copyright(context.TODO())
+	assert.True(t, errors.Is(err, yandex.ErrInvalidKey))
+}